@@ -1,43 +1,123 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
 	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
+	"github.com/zeddy-go/zeddy/health"
 	"log/slog"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
+	"time"
 )
 
-var moduleList = make([]Module, 0)
+const (
+	// DefaultShutdownTimeout 默认的整体停止超时时间, 可通过配置 app.shutdown.timeout 修改
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultModuleShutdownTimeout 默认的单个模块停止超时时间, 可通过配置 app.shutdown.moduleTimeout 修改
+	DefaultModuleShutdownTimeout = 10 * time.Second
+)
+
+// WithContainer 指定应用使用的容器, 为 nil 时使用 container.Default()
+func WithContainer(c *container.Container) func(*App) {
+	return func(a *App) {
+		a.container = c
+	}
+}
 
-var beforeWaits = make([]any, 0)
+// NewApp 创建一个独立的应用, 默认拥有自己的容器
+func NewApp(opts ...func(*App)) *App {
+	a := &App{
+		container: container.NewContainer(),
+		health:    health.NewRegistry(),
+	}
 
-var migrates []any
+	for _, opt := range opts {
+		opt(a)
+	}
 
-var seeds []any
+	return a
+}
+
+// App 应用, 持有模块列表、钩子以及容器, 多个应用之间互不影响
+type App struct {
+	container   *container.Container
+	moduleList  []Module
+	booted      []Module
+	beforeWaits []any
+	migrates    []any
+	seeds       []any
+	running     []*runningService
+	health      *health.Registry
+}
+
+// Container 返回应用的容器
+func (a *App) Container() *container.Container {
+	if a.container == nil {
+		return container.Default()
+	}
+
+	return a.container
+}
+
+// Health 返回应用的健康检查注册表, 模块可以向其注册检查项
+func (a *App) Health() *health.Registry {
+	return a.health
+}
 
 // BeforeWait 等待钩子
-func BeforeWait(funcs ...any) {
-	beforeWaits = append(beforeWaits, funcs...)
+func (a *App) BeforeWait(funcs ...any) {
+	a.beforeWaits = append(a.beforeWaits, funcs...)
+}
+
+func (a *App) RegisterMigrates(ms ...any) {
+	a.migrates = append(a.migrates, ms...)
+}
+
+func (a *App) RegisterSeeds(ss ...any) {
+	a.seeds = append(a.seeds, ss...)
 }
 
-func RegisterMigrates(ms ...any) {
-	migrates = append(migrates, ms...)
+func (a *App) Use(modules ...Module) {
+	for _, m := range modules {
+		if x, ok := m.(attachable); ok {
+			x.attach(a)
+		}
+	}
+	a.moduleList = append(a.moduleList, modules...)
 }
 
-func RegisterSeeds(ss ...any) {
-	seeds = append(seeds, ss...)
+type bootOpts struct {
+	// modules 需要启动的模块名称, 为 nil 时启动全部模块
+	modules []string
+	// migrate 是否执行注册的迁移和数据填充
+	migrate bool
 }
 
-func Use(modules ...Module) {
-	moduleList = append(moduleList, modules...)
+func (a *App) Boot() (err error) {
+	return a.boot(bootOpts{migrate: true})
 }
 
-func Boot() (err error) {
-	for _, module := range moduleList {
+func (a *App) boot(opts bootOpts) (err error) {
+	modules, err := a.modulesFor(opts.modules)
+	if err != nil {
+		return
+	}
+	a.booted, err = sortModules(modules)
+	if err != nil {
+		return
+	}
+
+	err = container.BindIn[*health.Registry](a.Container(), a.health)
+	if err != nil {
+		return
+	}
+
+	for _, module := range a.booted {
 		if m, ok := module.(Initable); ok {
 			err = m.Init()
 			if err != nil {
@@ -46,13 +126,13 @@ func Boot() (err error) {
 		}
 	}
 
-	if len(migrates) > 0 {
-		if !container.Has[database.Migrator]() {
+	if opts.migrate && len(a.migrates) > 0 {
+		if !container.HasIn[database.Migrator](a.Container()) {
 			err = errx.New("migrator not found, forget use it?")
 			return
 		}
-		err = container.Invoke(func(migrator database.Migrator) (err error) {
-			err = migrator.RegisterMigrates(migrates...)
+		err = container.InvokeIn(a.Container(), func(migrator database.Migrator) (err error) {
+			err = migrator.RegisterMigrates(a.migrates...)
 			if err != nil {
 				return
 			}
@@ -63,16 +143,14 @@ func Boot() (err error) {
 		}
 	}
 
-	if len(seeds) > 0 {
-		for _, seed := range seeds {
-			err = container.Invoke(seed)
-			if err != nil {
-				return
-			}
+	if opts.migrate {
+		err = a.seed()
+		if err != nil {
+			return
 		}
 	}
 
-	for _, module := range moduleList {
+	for _, module := range a.booted {
 		if m, ok := module.(Bootable); ok {
 			err = m.Boot()
 			if err != nil {
@@ -84,62 +162,143 @@ func Boot() (err error) {
 	return
 }
 
-func Start(wg *sync.WaitGroup) (n int) {
-	for _, m := range moduleList {
-		if module, ok := m.(Service); ok {
-			wg.Add(1)
-			go func(start func()) {
-				defer wg.Done()
-				start()
-			}(module.Start)
-			n++
+func (a *App) seed() (err error) {
+	for _, seed := range a.seeds {
+		err = container.InvokeIn(a.Container(), seed)
+		if err != nil {
+			return
 		}
 	}
 
 	return
 }
 
-func Stop() {
-	for _, m := range moduleList {
-		if module, ok := m.(Service); ok {
-			go module.Stop()
+// Start 按依赖顺序逐个启动服务, 每个服务就绪后才启动下一个.
+// 任意服务启动失败时, 已启动的服务会被逆序停止并返回错误
+func (a *App) Start(ctx context.Context) (n int, err error) {
+	for _, m := range a.booted {
+		s, ok := m.(Service)
+		if !ok {
+			continue
 		}
+
+		svc := newRunningService(moduleName(m), s)
+		a.running = append(a.running, svc)
+		err = svc.start(ctx)
+		if err != nil {
+			a.Stop()
+			return
+		}
+		n++
+	}
+	a.health.SetReady(true)
+
+	return
+}
+
+// Stop 按启动的逆序停止服务, 单个服务或整体超时后不再等待, 最后关闭容器释放其创建的资源
+func (a *App) Stop() {
+	a.health.SetReady(false)
+	timeout, moduleTimeout := a.shutdownTimeouts()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for i := len(a.running) - 1; i >= 0; i-- {
+		svc := a.running[i]
+		moduleCtx, moduleCancel := context.WithTimeout(ctx, moduleTimeout)
+		err := svc.stop(moduleCtx)
+		moduleCancel()
+		if err != nil {
+			slog.Warn("module stop failed", "module", svc.name, "error", err)
+		}
+		if ctx.Err() != nil {
+			slog.Warn("shutdown timeout, remaining modules are not waited", "timeout", timeout)
+			break
+		}
+	}
+	a.running = nil
+	a.close()
+}
+
+// close 关闭容器, 逆序释放容器创建的实例
+func (a *App) close() {
+	if err := a.Container().Close(); err != nil {
+		slog.Warn("close container failed", "error", err)
+	}
+}
+
+func (a *App) shutdownTimeouts() (timeout time.Duration, moduleTimeout time.Duration) {
+	timeout = DefaultShutdownTimeout
+	moduleTimeout = DefaultModuleShutdownTimeout
+
+	c, err := container.ResolveIn[*viper.Viper](a.Container())
+	if err != nil {
+		return
 	}
+	if t := c.GetDuration("app.shutdown.timeout"); t > 0 {
+		timeout = t
+	}
+	if t := c.GetDuration("app.shutdown.moduleTimeout"); t > 0 {
+		moduleTimeout = t
+	}
+
+	return
 }
 
-func StartAndWait() (err error) {
-	err = Boot()
+func (a *App) StartAndWait() (err error) {
+	err = a.Boot()
 	if err != nil {
 		return
 	}
 
-	var wg sync.WaitGroup
-	n := Start(&wg)
+	return a.wait()
+}
+
+// wait 启动服务并等待退出信号
+func (a *App) wait() (err error) {
+	n, err := a.Start(context.Background())
+	if err != nil {
+		return
+	}
 
 	if n == 0 {
 		slog.Info("nothing started, shutdown.")
-	} else {
-		if len(beforeWaits) > 0 {
-			for _, f := range beforeWaits {
-				err = container.Invoke(f)
-				if err != nil {
-					return
-				}
-			}
-		}
-		signals := make(chan os.Signal, 1)
-		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGKILL)
+		a.close()
+		return
+	}
 
-		for range signals {
-			signal.Stop(signals)
-			close(signals)
-			Stop()
+	for _, f := range a.beforeWaits {
+		err = container.InvokeIn(a.Container(), f)
+		if err != nil {
+			a.Stop()
+			return
 		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
 
-		println("wait module stop...")
-		wg.Wait()
-		println("bye bye~")
+	// 服务返回 nil 表示已完成工作, 全部完成或任意服务出错时退出
+	done := exited(a.running)
+	remaining := len(a.running)
+WAIT:
+	for remaining > 0 {
+		select {
+		case <-signals:
+			break WAIT
+		case svc := <-done:
+			if svc.err != nil {
+				err = errx.Wrap(svc.err, fmt.Sprintf("service <%s> exited unexpectedly", svc.name))
+				break WAIT
+			}
+			remaining--
+		}
 	}
 
+	println("wait module stop...")
+	a.Stop()
+	println("bye bye~")
+
 	return
 }
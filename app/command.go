@@ -0,0 +1,260 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/database"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// Command 命令行子命令
+type Command struct {
+	Name  string
+	Usage string
+	// Modules 执行命令需要的模块名称, 被依赖的模块会自动包含; 为 nil 时使用全部模块
+	Modules []string
+	// Migrate 为 true 时启动过程中会执行注册的迁移和数据填充
+	Migrate bool
+	// Run 在模块启动后执行, args 为子命令之后的参数
+	Run         func(a *App, args []string) error
+	Subcommands []*Command
+}
+
+// HasCommands 接口表示模块提供命令行子命令
+type HasCommands interface {
+	Commands() []*Command
+}
+
+func (c *Command) find(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+func (c *Command) printUsage(w io.Writer, prefix string) {
+	name := strings.TrimSpace(prefix + " " + c.Name)
+	if c.Run != nil || len(c.Subcommands) == 0 {
+		_, _ = fmt.Fprintf(w, "  %-24s %s\n", name, c.Usage)
+	}
+	for _, sub := range c.Subcommands {
+		sub.printUsage(w, name)
+	}
+}
+
+// Commands 返回应用的全部命令, 包括内置命令和模块提供的命令
+func (a *App) Commands() []*Command {
+	root := &Command{Subcommands: a.builtinCommands()}
+	for _, m := range a.moduleList {
+		if x, ok := m.(HasCommands); ok {
+			for _, cmd := range x.Commands() {
+				if exist := root.find(cmd.Name); exist != nil {
+					exist.Subcommands = append(exist.Subcommands, cmd.Subcommands...)
+				} else {
+					root.Subcommands = append(root.Subcommands, cmd)
+				}
+			}
+		}
+	}
+
+	return root.Subcommands
+}
+
+// Run 解析并执行命令, 没有参数时执行 serve
+func (a *App) Run(args []string) (err error) {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	root := &Command{Subcommands: a.Commands()}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		a.printUsage(os.Stdout, root)
+		return
+	}
+
+	cmd := root
+	var path []string
+	for len(args) > 0 {
+		sub := cmd.find(args[0])
+		if sub == nil {
+			break
+		}
+		cmd = sub
+		path = append(path, args[0])
+		args = args[1:]
+	}
+
+	if cmd.Run == nil {
+		a.printUsage(os.Stderr, cmd)
+		if len(args) > 0 {
+			err = errx.New(fmt.Sprintf("unknown command: %s", strings.Join(append(path, args[0]), " ")))
+		} else {
+			err = errx.New(fmt.Sprintf("command <%s> requires a subcommand", strings.Join(path, " ")))
+		}
+		return
+	}
+
+	defer a.close()
+	err = a.boot(bootOpts{
+		modules: cmd.Modules,
+		migrate: cmd.Migrate,
+	})
+	if err != nil {
+		return
+	}
+
+	return cmd.Run(a, args)
+}
+
+func (a *App) printUsage(w io.Writer, root *Command) {
+	_, _ = fmt.Fprintln(w, "Usage:")
+	for _, cmd := range root.Subcommands {
+		cmd.printUsage(w, "")
+	}
+}
+
+func (a *App) builtinCommands() []*Command {
+	return []*Command{
+		{
+			Name:    "serve",
+			Usage:   "boot all modules and start services",
+			Migrate: true,
+			Run: func(a *App, args []string) error {
+				return a.wait()
+			},
+		},
+		{
+			Name:  "migrate",
+			Usage: "database migrations",
+			Subcommands: []*Command{
+				{
+					Name:    "up",
+					Usage:   "[n] apply n or all pending migrations",
+					Modules: []string{"migrate"},
+					Run: func(a *App, args []string) error {
+						return a.migrate(args, database.Migrator.Up)
+					},
+				},
+				{
+					Name:    "down",
+					Usage:   "[n] roll back n or all migrations",
+					Modules: []string{"migrate"},
+					Run: func(a *App, args []string) error {
+						return a.migrate(args, database.Migrator.Down)
+					},
+				},
+				{
+					Name:    "status",
+					Usage:   "print current migration version",
+					Modules: []string{"migrate"},
+					Run: func(a *App, args []string) error {
+						return a.migrate(nil, func(migrator database.Migrator, _ int) (err error) {
+							version, dirty, err := migrator.Version()
+							if err != nil {
+								return
+							}
+							fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+							return
+						})
+					},
+				},
+			},
+		},
+		{
+			Name:  "container",
+			Usage: "dependency injection container",
+			Subcommands: []*Command{
+				{
+					Name:  "graph",
+					Usage: "[text|dot] print registered bindings and their dependencies",
+					Run: func(a *App, args []string) error {
+						graph := a.Container().Graph()
+						if len(args) > 0 && args[0] == "dot" {
+							return graph.WriteDOT(os.Stdout)
+						}
+						return graph.WriteText(os.Stdout)
+					},
+				},
+			},
+		},
+		{
+			Name:  "seed",
+			Usage: "run registered seeds",
+			Run: func(a *App, args []string) error {
+				return a.seed()
+			},
+		},
+	}
+}
+
+func (a *App) migrate(args []string, f func(migrator database.Migrator, stepNum int) error) (err error) {
+	var stepNum int
+	if len(args) > 0 {
+		stepNum, err = strconv.Atoi(args[0])
+		if err != nil {
+			return errx.Wrap(err, "step number is invalid")
+		}
+	}
+
+	return container.InvokeIn(a.Container(), func(migrator database.Migrator) (err error) {
+		err = migrator.RegisterMigrates(a.migrates...)
+		if err != nil {
+			return
+		}
+		return f(migrator, stepNum)
+	})
+}
+
+// modulesFor 返回指定名称的模块及其依赖的模块, names 为 nil 时返回全部模块
+func (a *App) modulesFor(names []string) (modules []Module, err error) {
+	if names == nil {
+		return a.moduleList, nil
+	}
+
+	index := make(map[string]Module, len(a.moduleList))
+	for _, m := range a.moduleList {
+		index[moduleName(m)] = m
+	}
+
+	need := make(map[string]bool)
+	var walk func(name string) error
+	walk = func(name string) error {
+		if need[name] {
+			return nil
+		}
+		m, ok := index[name]
+		if !ok {
+			return errx.New(fmt.Sprintf("module <%s> is required, forget use it?", name))
+		}
+		need[name] = true
+		if d, ok := m.(HasDependencies); ok {
+			for _, dep := range d.Dependencies() {
+				if e := walk(dep); e != nil {
+					return e
+				}
+			}
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err = walk(name); err != nil {
+			return
+		}
+	}
+
+	for _, m := range a.moduleList {
+		if need[moduleName(m)] {
+			modules = append(modules, m)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testCommandModule struct {
+	testModule
+	inited  *[]string
+	command bool
+	args    []string
+}
+
+func (t *testCommandModule) Init() error {
+	*t.inited = append(*t.inited, t.name)
+	return nil
+}
+
+func (t *testCommandModule) Commands() []*Command {
+	if !t.command {
+		return nil
+	}
+	return []*Command{
+		{
+			Name: "tool",
+			Subcommands: []*Command{
+				{
+					Name:    "echo",
+					Modules: []string{t.name},
+					Run: func(a *App, args []string) error {
+						t.args = args
+						return nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	var inited []string
+	a := NewApp()
+	cmd := &testCommandModule{testModule: testModule{name: "b", deps: []string{"a"}}, inited: &inited, command: true}
+	a.Use(
+		&testCommandModule{testModule: testModule{name: "a"}, inited: &inited},
+		cmd,
+		&testCommandModule{testModule: testModule{name: "c"}, inited: &inited},
+	)
+
+	require.NoError(t, a.Run([]string{"tool", "echo", "1", "2"}))
+	require.Equal(t, []string{"1", "2"}, cmd.args)
+	require.Equal(t, []string{"a", "b"}, inited)
+
+	require.Error(t, a.Run([]string{"tool"}))
+	require.Error(t, a.Run([]string{"tool", "unknown"}))
+}
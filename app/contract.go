@@ -1,14 +1,23 @@
 package app
 
+import "context"
+
 // HasSubModule 接口表示模块可以包含子模块
 type HasSubModule interface {
 	Register(subs ...Module) error
 }
 
+// HasName 接口表示模块有名称, 名称用于声明和解析模块间的依赖
 type HasName interface {
 	Name() string
 }
 
+// HasDependencies 接口表示模块依赖其他模块, 返回被依赖模块的名称.
+// 框架会保证被依赖的模块先初始化、先启动、后停止
+type HasDependencies interface {
+	Dependencies() []string
+}
+
 // Module 表示实现了这个接口的结构体是一个框架模块。
 type Module interface {
 	module()
@@ -24,9 +33,12 @@ type Bootable interface {
 	Boot() error
 }
 
+// Service 表示模块提供一个需要持续运行的服务
 type Service interface {
-	//Start 启动服务并阻塞, 框架一般会将这个方法作为协程调用, 报错应打日志记录
-	Start()
-	//Stop 停止服务并阻塞, 报错应打日志记录
-	Stop()
+	//Start 启动服务并阻塞直到服务停止, 框架会将这个方法作为协程调用.
+	//服务可以对外提供服务后必须调用 ready, 在调用 ready 之前返回视为启动失败.
+	//ctx 会在服务停止后被取消, 正常停止时应返回 nil
+	Start(ctx context.Context, ready func()) error
+	//Stop 停止服务并阻塞, ctx 到期后应尽快返回
+	Stop(ctx context.Context) error
 }
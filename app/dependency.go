@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// moduleName 返回模块名称, 未实现 HasName 的模块使用类型名
+func moduleName(m Module) string {
+	if n, ok := m.(HasName); ok {
+		return n.Name()
+	}
+
+	return reflect.TypeOf(m).String()
+}
+
+// sortModules 按依赖关系对模块进行拓扑排序, 没有依赖关系的模块保持注册顺序
+func sortModules(modules []Module) (sorted []Module, err error) {
+	index := make(map[string]int, len(modules))
+	for i, m := range modules {
+		name := moduleName(m)
+		if _, ok := index[name]; ok {
+			err = errx.New(fmt.Sprintf("module <%s> is used more than once", name))
+			return
+		}
+		index[name] = i
+	}
+
+	deps := make([][]int, len(modules))
+	for i, m := range modules {
+		d, ok := m.(HasDependencies)
+		if !ok {
+			continue
+		}
+		for _, dep := range d.Dependencies() {
+			j, ok := index[dep]
+			if !ok {
+				err = errx.New(fmt.Sprintf("module <%s> depends on <%s>, forget use it?", moduleName(m), dep))
+				return
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(modules))
+	sorted = make([]Module, 0, len(modules))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			name := moduleName(modules[i])
+			for k, item := range path {
+				if item == name {
+					path = append(path[k:], name)
+					break
+				}
+			}
+			return errx.New(fmt.Sprintf("module dependency cycle detected: %s", strings.Join(path, " -> ")))
+		}
+
+		states[i] = visiting
+		path = append(path, moduleName(modules[i]))
+		for _, j := range deps[i] {
+			if e := visit(j); e != nil {
+				return e
+			}
+		}
+		path = path[:len(path)-1]
+		states[i] = visited
+		sorted = append(sorted, modules[i])
+		return nil
+	}
+
+	for i := range modules {
+		if err = visit(i); err != nil {
+			sorted = nil
+			return
+		}
+	}
+
+	return
+}
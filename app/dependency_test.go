@@ -0,0 +1,76 @@
+package app
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testModule struct {
+	IsModule
+	name string
+	deps []string
+}
+
+func (t *testModule) Name() string {
+	return t.name
+}
+
+func (t *testModule) Dependencies() []string {
+	return t.deps
+}
+
+func names(modules []Module) (result []string) {
+	for _, m := range modules {
+		result = append(result, moduleName(m))
+	}
+	return
+}
+
+func TestSortModules(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		sorted, err := sortModules([]Module{
+			&testModule{name: "ginx", deps: []string{"gormx", "configx"}},
+			&testModule{name: "gormx", deps: []string{"configx"}},
+			&testModule{name: "other"},
+			&testModule{name: "configx"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"configx", "gormx", "ginx", "other"}, names(sorted))
+	})
+
+	t.Run("keep order", func(t *testing.T) {
+		sorted, err := sortModules([]Module{
+			&testModule{name: "a"},
+			&testModule{name: "b"},
+			&testModule{name: "c"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, names(sorted))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := sortModules([]Module{
+			&testModule{name: "ginx", deps: []string{"configx"}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "module <ginx> depends on <configx>")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := sortModules([]Module{
+			&testModule{name: "a", deps: []string{"b"}},
+			&testModule{name: "b", deps: []string{"c"}},
+			&testModule{name: "c", deps: []string{"a"}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a -> b -> c -> a")
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := sortModules([]Module{
+			&testModule{name: "a"},
+			&testModule{name: "a"},
+		})
+		require.Error(t, err)
+	})
+}
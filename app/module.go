@@ -1,5 +1,32 @@
 package app
 
-type IsModule struct{}
+import "github.com/zeddy-go/zeddy/container"
+
+// attachable 由 IsModule 实现, Use 时将模块关联到所属的应用
+type attachable interface {
+	attach(a *App)
+}
+
+type IsModule struct {
+	app *App
+}
 
 func (b IsModule) module() {}
+
+func (b *IsModule) attach(a *App) {
+	b.app = a
+}
+
+// App 返回模块所属的应用, 未被任何应用 Use 时返回默认应用
+func (b *IsModule) App() *App {
+	if b.app == nil {
+		return def
+	}
+
+	return b.app
+}
+
+// Container 返回模块所属应用的容器
+func (b *IsModule) Container() *container.Container {
+	return b.App().Container()
+}
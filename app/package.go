@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"os"
+)
+
+// def 默认应用, 使用 container.Default() 作为容器
+var def = NewApp(WithContainer(nil))
+
+// Default 返回默认应用, 包级函数都作用于默认应用
+func Default() *App {
+	return def
+}
+
+// BeforeWait 等待钩子
+func BeforeWait(funcs ...any) {
+	def.BeforeWait(funcs...)
+}
+
+func RegisterMigrates(ms ...any) {
+	def.RegisterMigrates(ms...)
+}
+
+func RegisterSeeds(ss ...any) {
+	def.RegisterSeeds(ss...)
+}
+
+func Use(modules ...Module) {
+	def.Use(modules...)
+}
+
+func Boot() (err error) {
+	return def.Boot()
+}
+
+func Start(ctx context.Context) (n int, err error) {
+	return def.Start(ctx)
+}
+
+func Stop() {
+	def.Stop()
+}
+
+func StartAndWait() (err error) {
+	return def.StartAndWait()
+}
+
+// Run 使用 os.Args 执行默认应用的命令
+func Run() (err error) {
+	return def.Run(os.Args[1:])
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+func newRunningService(name string, s Service) *runningService {
+	return &runningService{
+		name:    name,
+		service: s,
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// runningService 记录一个已启动服务的状态
+type runningService struct {
+	name    string
+	service Service
+	ready   chan struct{}
+	done    chan struct{}
+	err     error
+	cancel  context.CancelFunc
+}
+
+// start 在协程中启动服务, 并阻塞到服务就绪或启动失败
+func (r *runningService) start(ctx context.Context) (err error) {
+	var (
+		once     sync.Once
+		startCtx context.Context
+	)
+	startCtx, r.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(r.done)
+		r.err = r.service.Start(startCtx, func() {
+			once.Do(func() {
+				close(r.ready)
+			})
+		})
+	}()
+
+	select {
+	case <-r.ready:
+	case <-r.done:
+		if r.err != nil {
+			err = errx.Wrap(r.err, fmt.Sprintf("service <%s> start failed", r.name))
+		} else {
+			err = errx.New(fmt.Sprintf("service <%s> exited before ready", r.name))
+		}
+	case <-ctx.Done():
+		err = errx.Wrap(ctx.Err(), fmt.Sprintf("service <%s> start canceled", r.name))
+	}
+
+	return
+}
+
+// stop 停止服务并等待 Start 返回, ctx 到期后不再等待
+func (r *runningService) stop(ctx context.Context) (err error) {
+	select {
+	case <-r.done:
+		r.cancel()
+		return
+	default:
+	}
+
+	err = r.service.Stop(ctx)
+	r.cancel()
+	if err != nil {
+		return
+	}
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		err = errx.Wrap(ctx.Err(), fmt.Sprintf("wait service <%s> stop", r.name))
+	}
+
+	return
+}
+
+// exited 返回一个通道, 任意服务退出时会收到该服务
+func exited(services []*runningService) <-chan *runningService {
+	ch := make(chan *runningService, len(services))
+	for _, svc := range services {
+		go func(svc *runningService) {
+			<-svc.done
+			ch <- svc
+		}(svc)
+	}
+
+	return ch
+}
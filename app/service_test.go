@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/container"
+	"testing"
+)
+
+type testService struct {
+	IsModule
+	name     string
+	startErr error
+	events   *[]string
+	stop     chan struct{}
+}
+
+func (t *testService) Name() string {
+	return t.name
+}
+
+func (t *testService) Start(ctx context.Context, ready func()) error {
+	if t.startErr != nil {
+		return t.startErr
+	}
+	*t.events = append(*t.events, "start "+t.name)
+	ready()
+	<-t.stop
+	return nil
+}
+
+func (t *testService) Stop(ctx context.Context) error {
+	*t.events = append(*t.events, "stop "+t.name)
+	close(t.stop)
+	return nil
+}
+
+type testResource struct {
+	events *[]string
+}
+
+func (r *testResource) Close() error {
+	*r.events = append(*r.events, "close resource")
+	return nil
+}
+
+func TestStart(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		var events []string
+		a := NewApp()
+		a.Use(
+			&testService{name: "a", events: &events, stop: make(chan struct{})},
+			&testService{name: "b", events: &events, stop: make(chan struct{})},
+		)
+		require.NoError(t, a.Boot())
+		require.NoError(t, container.BindIn[*testResource](a.Container(), func() *testResource {
+			return &testResource{events: &events}
+		}))
+		_, err := container.ResolveIn[*testResource](a.Container())
+		require.NoError(t, err)
+		n, err := a.Start(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		a.Stop()
+		require.Equal(t, []string{"start a", "start b", "stop b", "stop a", "close resource"}, events)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		var events []string
+		a := NewApp()
+		a.Use(
+			&testService{name: "a", events: &events, stop: make(chan struct{})},
+			&testService{name: "b", events: &events, stop: make(chan struct{}), startErr: errors.New("listen failed")},
+			&testService{name: "c", events: &events, stop: make(chan struct{})},
+		)
+		require.NoError(t, a.Boot())
+		_, err := a.Start(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service <b> start failed")
+		require.Equal(t, []string{"start a", "stop a"}, events)
+		require.Empty(t, a.running)
+	})
+}
+
+func TestIsolatedApps(t *testing.T) {
+	a1 := NewApp()
+	a2 := NewApp()
+	m1 := &testModule{name: "a"}
+	m2 := &testModule{name: "a"}
+	a1.Use(m1)
+	a2.Use(m2)
+
+	require.Same(t, a1.Container(), m1.Container())
+	require.Same(t, a2.Container(), m2.Container())
+	require.NotSame(t, m1.Container(), m2.Container())
+	require.NoError(t, a1.Boot())
+	require.NoError(t, a2.Boot())
+}
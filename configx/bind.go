@@ -0,0 +1,215 @@
+package configx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+type binding struct {
+	load func(v *viper.Viper) (any, []string)
+}
+
+// Bind 将 prefix 对应的配置解析为结构体 T (或结构体指针) 并注册到容器中, prefix 为空时解析全部配置.
+// 字段通过 mapstructure 标签指定键名, default 标签指定默认值, validate 标签指定校验规则.
+// 所有绑定的缺失或不合法的配置会在 configx 模块 Boot 时一并报告
+func Bind[T any](c *container.Container, prefix string) (err error) {
+	m, err := container.ResolveIn[*Module](c)
+	if err != nil {
+		return errx.Wrap(err, "configx module not found, forget use it?")
+	}
+
+	load := func(v *viper.Viper) (result T, problems []string) {
+		value, problems := decode(v, prefix, reflect.TypeOf((*T)(nil)).Elem())
+		if len(problems) == 0 {
+			result = value.Interface().(T)
+		}
+		return
+	}
+
+	m.lock.Lock()
+	m.bindings = append(m.bindings, binding{
+		load: func(v *viper.Viper) (any, []string) {
+			return load(v)
+		},
+	})
+	m.lock.Unlock()
+
+	return container.BindIn[T](c, func() (result T, err error) {
+		result, problems := load(m.Viper())
+		if len(problems) > 0 {
+			err = errx.New("invalid config:\n  " + strings.Join(problems, "\n  "))
+		}
+		return
+	})
+}
+
+// checkBindings 校验全部绑定的配置, 返回所有问题
+func (m *Module) checkBindings() (err error) {
+	m.lock.Lock()
+	bindings := append([]binding(nil), m.bindings...)
+	m.lock.Unlock()
+
+	var problems []string
+	for _, b := range bindings {
+		_, p := b.load(m.Viper())
+		problems = append(problems, p...)
+	}
+	if len(problems) > 0 {
+		err = errx.New("invalid config:\n  "+strings.Join(problems, "\n  "), errx.WithDetailSlice(toAnySlice(problems)))
+	}
+
+	return
+}
+
+func toAnySlice(items []string) (result []any) {
+	result = make([]any, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	return
+}
+
+// decode 解析配置到类型 t 的新值, t 可以是结构体或结构体指针
+func decode(v *viper.Viper, prefix string, t reflect.Type) (result reflect.Value, problems []string) {
+	base := t
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	ptr := reflect.New(base)
+
+	if err := applyDefaults(ptr.Elem()); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %s", keyPath(prefix, ""), err.Error()))
+		return
+	}
+
+	var err error
+	if prefix == "" {
+		err = v.Unmarshal(ptr.Interface())
+	} else {
+		err = v.UnmarshalKey(prefix, ptr.Interface())
+	}
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %s", keyPath(prefix, ""), err.Error()))
+		return
+	}
+
+	problems = validate(prefix, ptr.Interface())
+	if len(problems) > 0 {
+		return
+	}
+
+	if t.Kind() == reflect.Pointer {
+		result = ptr
+	} else {
+		result = ptr.Elem()
+	}
+
+	return
+}
+
+// applyDefaults 使用 default 标签填充字段的默认值
+func applyDefaults(v reflect.Value) (err error) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err = applyDefaults(value); err != nil {
+				return
+			}
+			continue
+		}
+
+		def, ok := field.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+			),
+			WeaklyTypedInput: true,
+			Result:           value.Addr().Interface(),
+		})
+		if err != nil {
+			return err
+		}
+		if err = decoder.Decode(def); err != nil {
+			return errx.Wrap(err, fmt.Sprintf("invalid default value of field <%s>", field.Name))
+		}
+	}
+
+	return
+}
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+func validate(prefix string, value any) (problems []string) {
+	err := configValidator.Struct(value)
+	if err == nil {
+		return
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{fmt.Sprintf("%s: %s", keyPath(prefix, ""), err.Error())}
+	}
+
+	for _, e := range errs {
+		// Namespace 的第一段是结构体名称, 替换为配置前缀
+		field := e.Namespace()
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+		key := keyPath(prefix, field)
+		if e.Tag() == "required" {
+			problems = append(problems, fmt.Sprintf("%s is required", key))
+		} else if e.Param() != "" {
+			problems = append(problems, fmt.Sprintf("%s is invalid, failed on '%s=%s'", key, e.Tag(), e.Param()))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s is invalid, failed on '%s'", key, e.Tag()))
+		}
+	}
+
+	return
+}
+
+func keyPath(prefix string, field string) string {
+	switch {
+	case prefix == "" && field == "":
+		return "<root>"
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	default:
+		return prefix + "." + field
+	}
+}
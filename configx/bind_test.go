@@ -0,0 +1,67 @@
+package configx
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/container"
+	"testing"
+	"time"
+)
+
+type testDatabaseConfig struct {
+	DSN           string        `mapstructure:"dsn" validate:"required"`
+	SlowThreshold time.Duration `mapstructure:"slowThreshold" default:"200ms"`
+	Pool          struct {
+		MaxOpen int `mapstructure:"maxOpen" default:"10" validate:"gt=0"`
+	} `mapstructure:"pool"`
+}
+
+type testHttpConfig struct {
+	Addr string `mapstructure:"addr" validate:"required"`
+}
+
+type testBindModule struct {
+	app.IsModule
+}
+
+func (m *testBindModule) Init() (err error) {
+	err = Bind[testDatabaseConfig](m.Container(), "database")
+	if err != nil {
+		return
+	}
+	return Bind[*testHttpConfig](m.Container(), "http")
+}
+
+func TestBind(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		a := app.NewApp()
+		a.Use(NewModule(WithContent("database:\n  dsn: mysql://localhost\n  pool:\n    maxOpen: 5\nhttp:\n  addr: :8080\n")), &testBindModule{})
+		require.NoError(t, a.Boot())
+
+		db, err := container.ResolveIn[testDatabaseConfig](a.Container())
+		require.NoError(t, err)
+		require.Equal(t, "mysql://localhost", db.DSN)
+		require.Equal(t, 200*time.Millisecond, db.SlowThreshold)
+		require.Equal(t, 5, db.Pool.MaxOpen)
+
+		h, err := container.ResolveIn[*testHttpConfig](a.Container())
+		require.NoError(t, err)
+		require.Equal(t, ":8080", h.Addr)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		a := app.NewApp()
+		a.Use(NewModule(WithContent("database:\n  pool:\n    maxOpen: 0\n")), &testBindModule{})
+		err := a.Boot()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "database.dsn is required")
+		require.Contains(t, err.Error(), "database.pool.maxOpen is invalid")
+		require.Contains(t, err.Error(), "http.addr is required")
+	})
+
+	t.Run("without configx", func(t *testing.T) {
+		a := app.NewApp()
+		a.Use(&testBindModule{})
+		require.Error(t, a.Boot())
+	})
+}
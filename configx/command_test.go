@@ -0,0 +1,60 @@
+package configx
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	result := mask(map[string]any{
+		"mode": "local",
+		"database": map[string]any{
+			"dsn": "mysql://root:toor@(127.0.0.1:3306)/main",
+		},
+		"redis": map[string]any{
+			"addr":     "127.0.0.1:6379",
+			"password": "",
+		},
+		"jwt": map[string]any{
+			"secret_key": "abc",
+		},
+	})
+
+	require.Equal(t, map[string]any{
+		"mode": "local",
+		"database": map[string]any{
+			"dsn": masked,
+		},
+		"redis": map[string]any{
+			"addr":     "127.0.0.1:6379",
+			"password": "",
+		},
+		"jwt": map[string]any{
+			"secret_key": masked,
+		},
+	}, result)
+}
+
+func TestMaskList(t *testing.T) {
+	result := mask(map[string]any{
+		"database": map[string]any{
+			"replicas": []any{"mysql://u:secretpw@r1/db", "postgres://r2/db"},
+		},
+		"users": []any{
+			map[string]any{"name": "a", "password": "x"},
+		},
+		"tokens": []any{"a", "b"},
+		"ports":  []any{1, 2},
+	})
+
+	require.Equal(t, map[string]any{
+		"database": map[string]any{
+			"replicas": []any{"mysql://u:" + masked + "@r1/db", "postgres://r2/db"},
+		},
+		"users": []any{
+			map[string]any{"name": "a", "password": masked},
+		},
+		"tokens": []any{masked, masked},
+		"ports":  []any{1, 2},
+	}, result)
+}
@@ -4,10 +4,10 @@ import (
 	"github.com/spf13/viper"
 	"github.com/zeddy-go/zeddy/app"
 	"github.com/zeddy-go/zeddy/container"
-	"io"
 	"log/slog"
 	"os"
-	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -17,9 +17,24 @@ const (
 	ModeRelease = "release"
 )
 
-func WithPath(path string) func(*Module) {
+// WithPath 指定配置文件, 多个文件按顺序合并, 不存在的文件会被忽略
+func WithPath(paths ...string) func(*Module) {
 	return func(module *Module) {
-		module.path = path
+		module.paths = append(module.paths, paths...)
+	}
+}
+
+// WithEnvFile 指定 .env 文件, 多个文件按顺序合并, 不存在的文件会被忽略
+func WithEnvFile(paths ...string) func(*Module) {
+	return func(module *Module) {
+		module.envFiles = append(module.envFiles, paths...)
+	}
+}
+
+// WithSecretsDir 指定密钥目录, 目录中每个文件的文件名为键名, 内容为值
+func WithSecretsDir(dir string) func(*Module) {
+	return func(module *Module) {
+		module.secretsDir = dir
 	}
 }
 
@@ -41,58 +56,84 @@ func NewModule(opts ...func(*Module)) *Module {
 
 type Module struct {
 	app.IsModule
-	config string
-	path   string
+	config     string
+	paths      []string
+	envFiles   []string
+	secretsDir string
+	v          atomic.Pointer[viper.Viper]
+	origins    map[string]string
+	lock       sync.Mutex
+	subs       []*subscriber
+	validators []func(v *viper.Viper) error
+	bindings   []binding
 }
 
-func (m Module) Init() (err error) {
-	viper.SetConfigType("yaml")
+func (m *Module) Name() string {
+	return "configx"
+}
+
+// Viper 返回当前的配置. 重新加载时会替换为新的 *viper.Viper 而不修改旧的,
+// 需要读取最新配置的代码应每次通过 Viper 或从容器中解析获取, 不要长期持有
+func (m *Module) Viper() *viper.Viper {
+	return m.v.Load()
+}
 
-	if m.path != "" {
-		m.config = readConfig(m.path)
+func (m *Module) Init() (err error) {
+	// 默认应用启动时沿用全局 viper, 兼容直接读取全局配置的代码, 但重新加载的配置不会反映到全局 viper 中;
+	// 其他应用各自持有独立的 viper
+	var v *viper.Viper
+	if m.App() == app.Default() {
+		v = viper.GetViper()
+	} else {
+		v = viper.New()
 	}
+	configure(v)
 
-	err = viper.ReadConfig(strings.NewReader(m.config))
+	settings, origins, err := m.load()
 	if err != nil {
 		return
 	}
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "__"))
-	viper.AutomaticEnv()
-	err = container.Bind[*viper.Viper](viper.GetViper)
+	err = replaceConfig(v, settings)
 	if err != nil {
 		return
 	}
-
-	if viper.GetString("mode") == "" {
-		viper.Set("mode", ModeLocal)
+	v.SetDefault("mode", ModeLocal)
+	m.origins = origins
+	m.v.Store(v)
+	setLog(v)
+	Subscribe(m, "mode", func(string) { logLevel.Set(levelOf(m.Viper())) })
+	Subscribe(m, "showDebugLog", func(bool) { logLevel.Set(levelOf(m.Viper())) })
+
+	err = container.BindIn[*viper.Viper](m.Container(), m.Viper, container.NoSingleton())
+	if err != nil {
+		return
+	}
+	err = container.BindIn[*Module](m.Container(), m)
+	if err != nil {
+		return
 	}
-	setLog(viper.GetViper())
 
 	return
 }
 
-func setLog(c *viper.Viper) {
-	opts := &slog.HandlerOptions{
-		AddSource: true,
-	}
-	if c.GetString("mode") == ModeLocal || c.GetBool("showDebugLog") {
-		opts.Level = slog.LevelDebug
-	}
-
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, opts)))
+// Boot 校验全部通过 Bind 绑定的配置, 一次性报告所有缺失或不合法的键
+func (m *Module) Boot() (err error) {
+	return m.checkBindings()
 }
 
-func readConfig(path string) (result string) {
-	f, err := os.Open(path)
-	if err != nil {
-		return
-	}
-	defer f.Close()
+var logLevel = new(slog.LevelVar)
 
-	content, err := io.ReadAll(f)
-	if err != nil {
-		return
+func levelOf(c *viper.Viper) slog.Level {
+	if c.GetString("mode") == ModeLocal || c.GetBool("showDebugLog") {
+		return slog.LevelDebug
 	}
+	return slog.LevelInfo
+}
 
-	return string(content)
+// setLog 未使用 logx 模块时的默认日志: JSON 输出到标准输出, local 模式或开启 showDebugLog 时为 debug 级别.
+// 使用 logx 模块时会在其 Boot 中被替换
+func setLog(c *viper.Viper) {
+	logLevel.Set(levelOf(c))
+	opts := &slog.HandlerOptions{AddSource: true, Level: logLevel}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, opts)))
 }
@@ -0,0 +1,336 @@
+package configx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// 配置由多层来源合并而成, 后面的层覆盖前面的层:
+//  1. WithContent 指定的内容
+//  2. WithPath 指定的文件, 按指定的顺序
+//  3. 与 mode 对应的覆盖文件, 如 config.yaml 对应 config.release.yaml
+//  4. WithEnvFile 指定的 .env 文件, 按指定的顺序
+//  5. WithSecretsDir 指定目录中的文件, 文件名为键名, 文件内容为值
+//  6. 环境变量
+//
+// .env 文件, 密钥文件和环境变量的键名使用 "__" 分隔层级, 如 DATABASE__DSN 对应 database.dsn.
+// 文件格式由扩展名决定, 支持 yaml, json 和 toml, 没有扩展名时按 yaml 解析
+
+// modes 可以有覆盖文件的 mode
+var modes = []string{ModeLocal, ModeDevelop, ModeStaging, ModeRelease}
+
+type layer struct {
+	name     string
+	settings map[string]any
+}
+
+func newViper() *viper.Viper {
+	v := viper.New()
+	configure(v)
+	return v
+}
+
+func configure(v *viper.Viper) {
+	v.SetConfigType("yaml")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "__"))
+	v.AutomaticEnv()
+}
+
+// replaceConfig 使用 settings 替换 v 中的配置, 只能用于还没有被读取的新 viper
+func replaceConfig(v *viper.Viper, settings map[string]any) (err error) {
+	err = v.ReadConfig(strings.NewReader(""))
+	if err != nil {
+		return
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// envName 返回 key 对应的环境变量名
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "__"))
+}
+
+// load 读取并合并全部配置层, 返回合并后的配置和每个键的来源
+func (m *Module) load() (settings map[string]any, origins map[string]string, err error) {
+	var base []layer
+	if m.config != "" {
+		var l layer
+		l, err = parseLayer("content", "yaml", m.config)
+		if err != nil {
+			return
+		}
+		base = append(base, l)
+	}
+	for _, path := range m.paths {
+		var (
+			l  layer
+			ok bool
+		)
+		l, ok, err = fileLayer(path)
+		if err != nil {
+			return
+		}
+		if ok {
+			base = append(base, l)
+		}
+	}
+
+	var rest []layer
+	for _, path := range m.envFiles {
+		var (
+			l  layer
+			ok bool
+		)
+		l, ok, err = envFileLayer(path)
+		if err != nil {
+			return
+		}
+		if ok {
+			rest = append(rest, l)
+		}
+	}
+	if m.secretsDir != "" {
+		var l layer
+		l, err = secretsLayer(m.secretsDir)
+		if err != nil {
+			return
+		}
+		rest = append(rest, l)
+	}
+
+	// mode 可能来自任意一层, 先合并其他层确定 mode, 再插入对应的覆盖文件
+	v := newViper()
+	v.SetDefault("mode", ModeLocal)
+	for _, l := range append(append([]layer(nil), base...), rest...) {
+		if err = v.MergeConfigMap(l.settings); err != nil {
+			return
+		}
+	}
+	mode := v.GetString("mode")
+
+	layers := base
+	for _, path := range m.paths {
+		var (
+			l  layer
+			ok bool
+		)
+		l, ok, err = fileLayer(overlayPath(path, mode))
+		if err != nil {
+			return
+		}
+		if ok {
+			layers = append(layers, l)
+		}
+	}
+	layers = append(layers, rest...)
+
+	v = viper.New()
+	origins = make(map[string]string)
+	for _, l := range layers {
+		if err = v.MergeConfigMap(l.settings); err != nil {
+			return
+		}
+		flatten("", l.settings, func(key string) {
+			origins[key] = l.name
+		})
+	}
+	settings = v.AllSettings()
+
+	return
+}
+
+// overlayPath 返回 path 对应 mode 的覆盖文件, 如 config.yaml 对应 config.release.yaml
+func overlayPath(path string, mode string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + mode + ext
+}
+
+// watched 返回需要监听的文件和目录, 均为绝对路径
+func (m *Module) watched() (files map[string]bool, dirs []string, secrets string, err error) {
+	var names []string
+	for _, path := range m.paths {
+		names = append(names, path)
+		for _, mode := range modes {
+			names = append(names, overlayPath(path, mode))
+		}
+	}
+	names = append(names, m.envFiles...)
+
+	files = make(map[string]bool, len(names))
+	for _, name := range names {
+		if name, err = filepath.Abs(name); err != nil {
+			return
+		}
+		files[name] = true
+		dirs = append(dirs, filepath.Dir(name))
+	}
+	if m.secretsDir != "" {
+		if secrets, err = filepath.Abs(m.secretsDir); err != nil {
+			return
+		}
+		dirs = append(dirs, secrets)
+	}
+
+	return
+}
+
+func parseLayer(name string, format string, content string) (l layer, err error) {
+	v := viper.New()
+	v.SetConfigType(format)
+	err = v.ReadConfig(strings.NewReader(content))
+	if err != nil {
+		err = errx.Wrap(err, fmt.Sprintf("parse config <%s> failed", name))
+		return
+	}
+
+	l = layer{name: name, settings: v.AllSettings()}
+	return
+}
+
+// readFile 读取文件, 文件不存在时 ok 为 false
+func readFile(path string) (content string, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = errx.Wrap(err, fmt.Sprintf("read config <%s> failed", path))
+		return
+	}
+
+	return string(data), true, nil
+}
+
+func fileLayer(path string) (l layer, ok bool, err error) {
+	content, ok, err := readFile(path)
+	if !ok || err != nil {
+		return
+	}
+
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	if format == "" {
+		format = "yaml"
+	}
+	l, err = parseLayer("file:"+path, format, content)
+
+	return
+}
+
+func envFileLayer(path string) (l layer, ok bool, err error) {
+	content, ok, err := readFile(path)
+	if !ok || err != nil {
+		return
+	}
+
+	name := "dotenv:" + path
+	flat, err := parseLayer(name, "dotenv", content)
+	if err != nil {
+		return
+	}
+
+	l = layer{name: name, settings: make(map[string]any)}
+	for key, value := range flat.settings {
+		setKey(l.settings, key, value)
+	}
+
+	return
+}
+
+func secretsLayer(dir string) (l layer, err error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		err = errx.Wrap(err, fmt.Sprintf("read secrets dir <%s> failed", dir))
+		return
+	}
+
+	l = layer{name: "secret:" + dir, settings: make(map[string]any)}
+	for _, entry := range entries {
+		// kubernetes 挂载的目录中有 ..data 等隐藏文件
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		var data []byte
+		data, err = os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			err = errx.Wrap(err, fmt.Sprintf("read secret <%s> failed", entry.Name()))
+			return
+		}
+		setKey(l.settings, entry.Name(), strings.TrimRight(string(data), "\r\n"))
+	}
+
+	return
+}
+
+// setKey 将 "__" 或 "." 分隔的键名设置到嵌套的 settings 中
+func setKey(settings map[string]any, key string, value any) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(key, "__", ".")), ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			settings[part] = next
+		}
+		settings = next
+	}
+	settings[parts[len(parts)-1]] = value
+}
+
+// flatten 遍历 settings 中全部叶子节点的完整键名
+func flatten(prefix string, settings map[string]any, f func(key string)) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if x, ok := value.(map[string]any); ok && len(x) > 0 {
+			flatten(key, x, f)
+		} else {
+			f(key)
+		}
+	}
+}
+
+// Origin 返回 key 的生效值来自哪一层, 如 "file:config.yaml", "env:DATABASE__DSN".
+// 只有默认值时返回 "default", 未设置时返回空字符串
+func (m *Module) Origin(key string) string {
+	key = strings.ToLower(key)
+	if env := envName(key); os.Getenv(env) != "" {
+		return "env:" + env
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if origin, ok := m.origins[key]; ok {
+		return origin
+	}
+	if v := m.Viper(); v != nil && v.IsSet(key) {
+		return "default"
+	}
+
+	return ""
+}
+
+// Origins 返回全部生效的叶子键及其来源, 按键名排序
+func (m *Module) Origins() (keys []string, origins []string) {
+	flatten("", m.Viper().AllSettings(), func(key string) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	for _, key := range keys {
+		origins = append(origins, m.Origin(key))
+	}
+
+	return
+}
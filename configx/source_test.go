@@ -0,0 +1,60 @@
+package configx
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/app"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	base := write("config.yaml", "mode: release\nname: base\ndatabase:\n  dsn: base\n  slowThreshold: 1s\nhttp:\n  addr: :80\n")
+	write("config.release.yaml", "name: overlay\n")
+	write("config.local.yaml", "name: local\n")
+	extra := write("extra.json", `{"http": {"addr": ":8080"}, "cors": true}`)
+	tomlFile := write("other.toml", "[redis]\naddr = \"localhost:6379\"\n")
+	envFile := write(".env", "DATABASE__DSN=dotenv\nREDIS__DB=2\n")
+	write("secrets/database__password", "secret\n")
+	write("secrets/..data", "ignored")
+	t.Setenv("HTTP__ADDR", ":9090")
+
+	a := app.NewApp()
+	m := NewModule(
+		WithContent("name: content\nshowDebugLog: true\n"),
+		WithPath(base, extra, tomlFile, filepath.Join(dir, "missing.yaml")),
+		WithEnvFile(envFile),
+		WithSecretsDir(filepath.Join(dir, "secrets")),
+	)
+	a.Use(m)
+	require.NoError(t, a.Boot())
+
+	require.Equal(t, "overlay", m.Viper().GetString("name"))
+	require.True(t, m.Viper().GetBool("showDebugLog"))
+	require.Equal(t, "dotenv", m.Viper().GetString("database.dsn"))
+	require.Equal(t, "1s", m.Viper().GetString("database.slowThreshold"))
+	require.Equal(t, "secret", m.Viper().GetString("database.password"))
+	require.Equal(t, ":9090", m.Viper().GetString("http.addr"))
+	require.Equal(t, "localhost:6379", m.Viper().GetString("redis.addr"))
+	require.Equal(t, 2, m.Viper().GetInt("redis.db"))
+
+	require.Equal(t, "content", m.Origin("showDebugLog"))
+	require.Equal(t, "file:"+filepath.Join(dir, "config.release.yaml"), m.Origin("name"))
+	require.Equal(t, "file:"+base, m.Origin("database.slowThreshold"))
+	require.Equal(t, "file:"+extra, m.Origin("cors"))
+	require.Equal(t, "dotenv:"+envFile, m.Origin("database.dsn"))
+	require.Equal(t, "secret:"+filepath.Join(dir, "secrets"), m.Origin("database.password"))
+	require.Equal(t, "env:HTTP__ADDR", m.Origin("http.addr"))
+	require.Equal(t, "", m.Origin("notExist"))
+
+	keys, _ := m.Origins()
+	require.NotContains(t, keys, "..data")
+}
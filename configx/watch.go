@@ -0,0 +1,210 @@
+package configx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// reloadDelay 文件变化后等待的时间, 编辑器保存时通常会产生多个事件
+const reloadDelay = 100 * time.Millisecond
+
+type subscriber struct {
+	key string
+	// decode 将新配置解析为订阅的类型, 失败时拒绝本次重新加载
+	decode func(v *viper.Viper) (any, error)
+	notify func(value any)
+}
+
+// Subscribe 订阅 key 对应的配置, key 为空时订阅全部配置.
+// 配置重新加载且该部分发生变化时 f 会收到解析后的新配置, 解析失败的配置不会被应用
+func Subscribe[T any](m *Module, key string, f func(T)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.subs = append(m.subs, &subscriber{
+		key: key,
+		decode: func(v *viper.Viper) (result any, err error) {
+			var value T
+			if key == "" {
+				err = v.Unmarshal(&value)
+			} else {
+				err = v.UnmarshalKey(key, &value)
+			}
+			if err != nil {
+				err = errx.Wrap(err, fmt.Sprintf("decode config <%s> failed", key))
+				return
+			}
+			return value, nil
+		},
+		notify: func(value any) {
+			f(value.(T))
+		},
+	})
+}
+
+// Validate 注册配置校验函数, 重新加载的配置校验失败时不会被应用
+func (m *Module) Validate(f func(v *viper.Viper) error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.validators = append(m.validators, f)
+}
+
+// Reload 从文件重新加载配置, 新配置校验通过后才会被应用并通知订阅者
+func (m *Module) Reload() (err error) {
+	if !m.hasFiles() {
+		return errx.New("config is not loaded from file, can not reload")
+	}
+
+	settings, origins, err := m.load()
+	if err != nil {
+		return
+	}
+	next := newViper()
+	err = replaceConfig(next, settings)
+	if err != nil {
+		return errx.Wrap(err, "parse config failed")
+	}
+	next.SetDefault("mode", ModeLocal)
+
+	changes, err := m.apply(origins, next)
+	if err != nil {
+		return
+	}
+
+	// 在锁外通知, 订阅者可以在回调中继续读取或订阅配置
+	for _, c := range changes {
+		c.sub.notify(c.value)
+	}
+
+	return
+}
+
+type change struct {
+	sub   *subscriber
+	value any
+}
+
+// apply 校验新配置, 通过后替换当前配置并返回需要通知的变化. 旧的 viper 不会被修改, 并发读取是安全的
+func (m *Module) apply(origins map[string]string, next *viper.Viper) (changes []change, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, validate := range m.validators {
+		if err = validate(next); err != nil {
+			err = errx.Wrap(err, "validate config failed")
+			return
+		}
+	}
+
+	current := m.Viper()
+	for _, sub := range m.subs {
+		if reflect.DeepEqual(settingOf(current, sub.key), settingOf(next, sub.key)) {
+			continue
+		}
+		var value any
+		value, err = sub.decode(next)
+		if err != nil {
+			return
+		}
+		changes = append(changes, change{sub: sub, value: value})
+	}
+
+	m.v.Store(next)
+	m.origins = origins
+
+	return
+}
+
+// settingOf 返回 key 对应的配置, key 为空时返回全部配置
+func settingOf(v *viper.Viper, key string) any {
+	if key == "" {
+		return v.AllSettings()
+	}
+
+	return v.Get(key)
+}
+
+// hasFiles 配置是否来自文件
+func (m *Module) hasFiles() bool {
+	return len(m.paths) > 0 || len(m.envFiles) > 0 || m.secretsDir != ""
+}
+
+// Start 监听配置文件的变化并自动重新加载, 配置不来自文件时直接返回
+func (m *Module) Start(ctx context.Context, ready func()) (err error) {
+	if !m.hasFiles() {
+		ready()
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errx.Wrap(err, "create config watcher failed")
+	}
+	defer watcher.Close()
+
+	// 监听所在目录, 编辑器保存文件时可能会删除并重新创建文件
+	files, dirs, secrets, err := m.watched()
+	if err != nil {
+		return
+	}
+	watching := make(map[string]bool)
+	for _, dir := range dirs {
+		if watching[dir] {
+			continue
+		}
+		watching[dir] = true
+		if _, e := os.Stat(dir); e != nil {
+			continue
+		}
+		if err = watcher.Add(dir); err != nil {
+			return errx.Wrap(err, "watch config failed")
+		}
+	}
+	ready()
+
+	changed := func(name string) bool {
+		name = filepath.Clean(name)
+		return files[name] || (secrets != "" && filepath.Dir(name) == secrets)
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if changed(event.Name) && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				timer.Reset(reloadDelay)
+			}
+		case e, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("config watcher error", "error", e)
+		case <-timer.C:
+			if e := m.Reload(); e != nil {
+				slog.Error("reload config failed, keep using the current config", "error", e)
+			} else {
+				slog.Info("config reloaded")
+			}
+		}
+	}
+}
+
+func (m *Module) Stop(ctx context.Context) error {
+	return nil
+}
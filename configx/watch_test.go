@@ -0,0 +1,155 @@
+package configx
+
+import (
+	"errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/container"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("database:\n  slowThreshold: 200ms\ncors: false\n")
+
+	a := app.NewApp()
+	m := NewModule(WithPath(path))
+	a.Use(m)
+	require.NoError(t, a.Boot())
+
+	var (
+		thresholds []time.Duration
+		cors       []bool
+	)
+	Subscribe(m, "database.slowThreshold", func(d time.Duration) {
+		thresholds = append(thresholds, d)
+	})
+	Subscribe(m, "cors", func(enabled bool) {
+		cors = append(cors, enabled)
+	})
+	m.Validate(func(v *viper.Viper) error {
+		if v.GetDuration("database.slowThreshold") <= 0 {
+			return errors.New("slowThreshold must be positive")
+		}
+		return nil
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		write("database:\n  slowThreshold: 1s\ncors: false\n")
+		require.NoError(t, m.Reload())
+		require.Equal(t, []time.Duration{time.Second}, thresholds)
+		require.Empty(t, cors)
+		require.Equal(t, time.Second, m.Viper().GetDuration("database.slowThreshold"))
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		write("database: [")
+		require.Error(t, m.Reload())
+		require.Equal(t, time.Second, m.Viper().GetDuration("database.slowThreshold"))
+	})
+
+	t.Run("validate failed", func(t *testing.T) {
+		write("database:\n  slowThreshold: -1s\ncors: true\n")
+		require.Error(t, m.Reload())
+		require.Len(t, thresholds, 1)
+		require.Empty(t, cors)
+		require.False(t, m.Viper().GetBool("cors"))
+	})
+
+	t.Run("decode failed", func(t *testing.T) {
+		write("database:\n  slowThreshold: 1s\ncors: abc\n")
+		require.Error(t, m.Reload())
+		require.False(t, m.Viper().GetBool("cors"))
+	})
+}
+
+func TestReloadConcurrentRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("name: a\n"), 0644))
+
+	a := app.NewApp()
+	m := NewModule(WithPath(path))
+	a.Use(m)
+	require.NoError(t, a.Boot())
+
+	held, err := container.ResolveIn[*viper.Viper](a.Container())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					_ = m.Viper().GetString("name")
+					_ = held.AllSettings()
+				}
+			}
+		}()
+	}
+
+	for _, name := range []string{"b", "c", "d"} {
+		require.NoError(t, os.WriteFile(path, []byte("name: "+name+"\n"), 0644))
+		require.NoError(t, m.Reload())
+	}
+	close(done)
+	wg.Wait()
+
+	require.Equal(t, "d", m.Viper().GetString("name"))
+	current, err := container.ResolveIn[*viper.Viper](a.Container())
+	require.NoError(t, err)
+	require.Equal(t, "d", current.GetString("name"))
+	// 已经持有的旧配置不会被修改
+	require.Equal(t, "a", held.GetString("name"))
+}
+
+func TestReloadLogLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("mode: release\n"), 0644))
+
+	a := app.NewApp()
+	m := NewModule(WithPath(path))
+	a.Use(m)
+	require.NoError(t, a.Boot())
+	require.Equal(t, slog.LevelInfo, logLevel.Level())
+
+	require.NoError(t, os.WriteFile(path, []byte("mode: release\nshowDebugLog: true\n"), 0644))
+	require.NoError(t, m.Reload())
+	require.Equal(t, slog.LevelDebug, logLevel.Level())
+}
+
+func TestReloadSubscribeAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("name: a\n"), 0644))
+
+	a := app.NewApp()
+	m := NewModule(WithPath(path))
+	a.Use(m)
+	require.NoError(t, a.Boot())
+
+	var names []string
+	Subscribe(m, "", func(c struct{ Name string }) {
+		names = append(names, c.Name)
+	})
+
+	require.NoError(t, m.Reload())
+	require.Empty(t, names)
+
+	require.NoError(t, os.WriteFile(path, []byte("name: b\n"), 0644))
+	require.NoError(t, m.Reload())
+	require.Equal(t, []string{"b"}, names)
+}
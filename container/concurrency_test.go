@@ -0,0 +1,118 @@
+package container
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentSingleton(t *testing.T) {
+	type Slow struct{ N int32 }
+	type Dependent struct{ S *Slow }
+
+	c := NewContainer()
+	var calls atomic.Int32
+	require.NoError(t, BindIn[*Slow](c, func() *Slow {
+		time.Sleep(10 * time.Millisecond)
+		return &Slow{N: calls.Add(1)}
+	}))
+	require.NoError(t, BindIn[*Dependent](c, func(s *Slow) *Dependent {
+		return &Dependent{S: s}
+	}))
+
+	var wg sync.WaitGroup
+	results := make([]*Dependent, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d, err := ResolveIn[*Dependent](c)
+			require.NoError(t, err)
+			results[i] = d
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+	for _, d := range results {
+		require.Same(t, results[0], d)
+	}
+}
+
+func TestConcurrentBindResolveInvoke(t *testing.T) {
+	type Value struct{ Key string }
+
+	c := NewContainer()
+	require.NoError(t, BindIn[*Value](c, &Value{Key: "default"}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, BindIn[*Value](c, func() *Value {
+				return &Value{Key: key}
+			}, WithKey(key)))
+		}()
+		go func() {
+			defer wg.Done()
+			v, err := ResolveIn[*Value](c)
+			require.NoError(t, err)
+			require.Equal(t, "default", v.Key)
+		}()
+		go func() {
+			defer wg.Done()
+			require.NoError(t, InvokeIn(c, func(v *Value) {
+				require.Equal(t, "default", v.Key)
+			}))
+			require.True(t, HasIn[*Value](c))
+		}()
+		go func() {
+			defer wg.Done()
+			scope := c.NewScope()
+			require.NoError(t, BindIn[*Value](scope, &Value{Key: "scope"}))
+			v, err := ResolveIn[*Value](scope)
+			require.NoError(t, err)
+			require.Equal(t, "scope", v.Key)
+			require.NoError(t, scope.Close())
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		v, err := ResolveIn[*Value](c, WithResolveKey(key))
+		require.NoError(t, err)
+		require.Equal(t, key, v.Key)
+	}
+}
+
+func TestConcurrentScoped(t *testing.T) {
+	type Unit struct{}
+
+	c := NewContainer()
+	var calls atomic.Int32
+	require.NoError(t, BindIn[*Unit](c, func() *Unit {
+		calls.Add(1)
+		return &Unit{}
+	}, Scoped()))
+
+	scope := c.NewScope()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ResolveIn[*Unit](scope)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -6,21 +6,33 @@ import (
 	"fmt"
 	"github.com/zeddy-go/zeddy/errx"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound           = errors.New("not found")
+	ErrCircularDependency = errors.New("circular dependency")
 )
 
 type provider struct {
-	Value     reflect.Value
+	Value reflect.Value
+	// Auto 没有 provider 函数, 由容器创建 Type 的实例并注入字段
+	Auto      bool
+	Type      reflect.Type
 	Singleton bool
+	Scoped    bool
+	Dispose   reflect.Value
+	// Keys provider 参数的键, 由 Keyed 指定
+	Keys map[int]string
 }
 
 type bindOpts struct {
 	Singleton bool
+	Scoped    bool
 	Key       string
+	Dispose   reflect.Value
 }
 
 type resolveOpts struct {
@@ -33,6 +45,22 @@ func NoSingleton() func(*bindOpts) {
 	}
 }
 
+// Scoped 每个作用域内只创建一个实例, 只能从 NewScope 创建的作用域中解析
+func Scoped() func(*bindOpts) {
+	return func(opts *bindOpts) {
+		opts.Scoped = true
+	}
+}
+
+// OnDispose 设置实例的释放函数, 形如 func(T) 或 func(T) error.
+// 释放函数在创建实例的容器 Close 时执行, 作用域实例即在作用域结束时执行.
+// 没有设置时, 由 provider 创建的单例和作用域实例实现了 Disposer 或 io.Closer 的会被自动释放
+func OnDispose(f any) func(*bindOpts) {
+	return func(opts *bindOpts) {
+		opts.Dispose = reflect.ValueOf(f)
+	}
+}
+
 func WithKey(key string) func(*bindOpts) {
 	return func(opts *bindOpts) {
 		opts.Key = key
@@ -47,14 +75,157 @@ func WithResolveKey(key string) func(*resolveOpts) {
 
 func NewContainer() *Container {
 	return &Container{
-		providers: make(map[reflect.Type]map[string]*provider),
-		instances: make(map[reflect.Type]map[string]reflect.Value),
+		providers:  make(map[reflect.Type]map[string]*provider),
+		instances:  make(map[reflect.Type]map[string]reflect.Value),
+		building:   make(map[entry]*sync.Mutex),
+		order:      make(map[entry]uint64),
+		decorators: make(map[reflect.Type][]reflect.Value),
 	}
 }
 
+// bindSeq 绑定的全局序号, 解析切片时按绑定顺序排列
+var bindSeq atomic.Uint64
+
+type entry struct {
+	t   reflect.Type
+	key string
+}
+
+// Container 依赖注入容器, 可以并发使用
 type Container struct {
+	parent *Container
+	// lock 保护 providers, instances, building, order, decorators 和 disposers
+	lock      sync.RWMutex
 	providers map[reflect.Type]map[string]*provider
 	instances map[reflect.Type]map[string]reflect.Value
+	// building 每个单例或作用域实例创建时持有的锁, 保证并发解析时只创建一次
+	building map[entry]*sync.Mutex
+	// order 显式绑定的顺序, 解析切片时使用
+	order      map[entry]uint64
+	decorators map[reflect.Type][]reflect.Value
+	disposers  []func() error
+}
+
+// NewScope 创建子作用域, 作用域可以解析父容器中绑定的类型, 绑定到作用域的类型只在作用域内可见.
+// 单例在绑定它的容器中创建, Scoped 绑定的类型在每个作用域中创建一次, 使用结束后需要调用 Close
+func (c *Container) NewScope() *Container {
+	scope := NewContainer()
+	scope.parent = c
+	return scope
+}
+
+func (c *Container) isScope() bool {
+	return c.parent != nil
+}
+
+// Close 逆序执行由该容器创建的实例的释放函数
+func (c *Container) Close() (err error) {
+	c.lock.Lock()
+	disposers := c.disposers
+	c.disposers = nil
+	c.lock.Unlock()
+
+	for i := len(disposers) - 1; i >= 0; i-- {
+		err = errors.Join(err, disposers[i]())
+	}
+
+	return
+}
+
+// track 记录 provider 创建的需要释放的实例
+func (c *Container) track(p *provider, value reflect.Value) {
+	dispose := p.Dispose
+	if !dispose.IsValid() && (p.Singleton || p.Scoped) {
+		dispose = autoDispose(value)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.trackLocked(dispose, value)
+}
+
+func (c *Container) trackLocked(dispose reflect.Value, value reflect.Value) {
+	if !dispose.IsValid() {
+		return
+	}
+
+	c.disposers = append(c.disposers, func() (err error) {
+		results := dispose.Call([]reflect.Value{value})
+		if len(results) > 0 && !results[0].IsNil() {
+			err = results[0].Interface().(error)
+		}
+		return
+	})
+}
+
+func checkDispose(t reflect.Type, dispose reflect.Value) error {
+	ft := dispose.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || !t.AssignableTo(ft.In(0)) || ft.NumOut() > 1 ||
+		(ft.NumOut() == 1 && ft.Out(0) != reflect.TypeOf((*error)(nil)).Elem()) {
+		return errx.New(fmt.Sprintf("dispose of <%s> should be func(%s) or func(%s) error", t, t, t))
+	}
+
+	return nil
+}
+
+// find 从当前容器开始向上查找绑定, 离当前容器最近的实例或 provider 优先,
+// 因此作用域中重新绑定的类型不会被父容器中已创建的单例覆盖. 返回 provider 时同时返回绑定它的容器
+func (c *Container) find(t reflect.Type, key string) (value reflect.Value, p *provider, owner *Container) {
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		value, ok := cur.instances[t][key]
+		p = cur.providers[t][key]
+		cur.lock.RUnlock()
+		if ok {
+			return value, nil, cur
+		}
+		if p != nil {
+			return reflect.Value{}, p, cur
+		}
+	}
+
+	return
+}
+
+// instance 返回当前容器中已创建的实例
+func (c *Container) instance(t reflect.Type, key string) (value reflect.Value, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, ok = c.instances[t][key]
+	return
+}
+
+// buildLock 返回创建 t 的实例时需要持有的锁
+func (c *Container) buildLock(t reflect.Type, key string) *sync.Mutex {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e := entry{t: t, key: key}
+	l, ok := c.building[e]
+	if !ok {
+		l = &sync.Mutex{}
+		c.building[e] = l
+	}
+
+	return l
+}
+
+func (c *Container) cache(t reflect.Type, key string, value reflect.Value) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cacheLocked(t, key, value)
+}
+
+func (c *Container) cacheLocked(t reflect.Type, key string, value reflect.Value) {
+	group, ok := c.instances[t]
+	if !ok {
+		group = make(map[string]reflect.Value)
+		c.instances[t] = group
+	}
+	group[key] = value
 }
 
 func (c *Container) Bind(t reflect.Type, value reflect.Value, opts ...func(*bindOpts)) (err error) {
@@ -64,12 +235,27 @@ func (c *Container) Bind(t reflect.Type, value reflect.Value, opts ...func(*bind
 	for _, opt := range opts {
 		opt(options)
 	}
+	if options.Dispose.IsValid() {
+		if err = checkDispose(t, options.Dispose); err != nil {
+			return
+		}
+	}
+
+	value, keys := unwrapKeyed(value)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	delete(c.instances, t)
-	if canBindConsistent(t, value) {
+	// 重新绑定时丢弃同一个键原有的绑定和已创建的实例
+	delete(c.providers[t], options.Key)
+	delete(c.instances[t], options.Key)
+	c.order[entry{t: t, key: options.Key}] = bindSeq.Add(1)
+	if !value.IsValid() {
+		err = c.bindAuto(t, options)
+	} else if canBindConsistent(t, value) {
 		err = c.bindInstance(t, value, options)
 	} else if canBindProvider(t, value) {
-		err = c.bindProvider(t, value, options)
+		err = c.bindProvider(t, value, keys, options)
 	} else {
 		err = errx.New(fmt.Sprintf("can not bind <%s> to <%s>", value.Type(), t))
 	}
@@ -98,12 +284,8 @@ func (c *Container) bindInstance(t reflect.Type, value reflect.Value, options *b
 		}
 	}
 
-	group, ok := c.instances[t]
-	if !ok {
-		group = make(map[string]reflect.Value)
-		c.instances[t] = group
-	}
-	group[options.Key] = value
+	c.cacheLocked(t, options.Key, value)
+	c.trackLocked(options.Dispose, value)
 
 	return
 }
@@ -142,7 +324,7 @@ func canBindProvider(t reflect.Type, value reflect.Value) bool {
 	return false
 }
 
-func (c *Container) bindProvider(t reflect.Type, value reflect.Value, options *bindOpts) (err error) {
+func (c *Container) bindProvider(t reflect.Type, value reflect.Value, keys map[int]string, options *bindOpts) (err error) {
 	group, ok := c.providers[t]
 	if !ok {
 		group = make(map[string]*provider)
@@ -151,6 +333,9 @@ func (c *Container) bindProvider(t reflect.Type, value reflect.Value, options *b
 	group[options.Key] = &provider{
 		Value:     value,
 		Singleton: options.Singleton,
+		Scoped:    options.Scoped,
+		Dispose:   options.Dispose,
+		Keys:      keys,
 	}
 
 	return
@@ -165,118 +350,175 @@ func (c *Container) Resolve(t reflect.Type, opts ...func(*resolveOpts)) (result
 }
 
 func (c *Container) resolve(ctx context.Context, t reflect.Type, opts *resolveOpts) (result reflect.Value, err error) {
-	group, ok := c.instances[t]
-	if ok {
-		result, ok = group[opts.Key]
-		if ok {
+	result, f, owner := c.find(t, opts.Key)
+	if result.IsValid() {
+		return
+	}
+
+	// 需要在获取创建锁之前检查, 否则循环依赖会在同一个 goroutine 中死锁
+	if err = checkCycle(ctx, entry{t: t, key: opts.Key}); err != nil {
+		return
+	}
+
+	if f != nil {
+		return c.resolveProvider(ctx, t, opts.Key, f, owner)
+	}
+
+	if t.Kind() == reflect.Slice && opts.Key == "" {
+		result, err = c.resolveAll(ctx, t)
+		if err != nil || result.IsValid() {
 			return
 		}
 	}
 
-	var chain []reflect.Type
-	tmp := ctx.Value("chain")
-	if tmp == nil {
-		chain = []reflect.Type{}
-		ctx = context.WithValue(ctx, "chain", chain)
-	} else {
-		chain = tmp.([]reflect.Type)
+	result, err = c.resolveSlow(ctx, t, opts)
+	if err != nil {
+		return
 	}
+	if !result.IsValid() {
+		err = errx.Wrap(ErrNotFound, fmt.Sprintf("type <%s>", t.String()))
+		return
+	}
+
+	return
+}
 
+type chainKey struct{}
+
+// chainOf 返回 ctx 中正在解析的类型链
+func chainOf(ctx context.Context) []entry {
+	chain, _ := ctx.Value(chainKey{}).([]entry)
+	return chain
+}
+
+// checkCycle e 已经在解析链中时返回包含完整解析链的错误
+func checkCycle(ctx context.Context, e entry) error {
+	chain := chainOf(ctx)
 	for _, item := range chain {
-		if item == t {
-			result = reflect.New(t).Elem()
-			return
+		if item == e {
+			return errx.Wrap(ErrCircularDependency, fmt.Sprintf("resolve <%s>", formatChain(append(chain[:len(chain):len(chain)], e))))
 		}
 	}
 
-	group2, ok := c.providers[t]
-	if ok {
-		var f *provider
-		f, ok = group2[opts.Key]
-		if ok {
-			chain = append(chain, t)
-			ctx = context.WithValue(ctx, "chain", chain)
-			result, err = c.invokeAndGetType(ctx, f.Value, t)
-			if err != nil {
-				return
-			}
+	return nil
+}
 
-			if f.Singleton {
-				group, ok = c.instances[t]
-				if !ok {
-					group = make(map[string]reflect.Value)
-					c.instances[t] = group
-				}
-				group[opts.Key] = result
-			}
+// enter 将 e 加入解析链
+func enter(ctx context.Context, e entry) context.Context {
+	chain := chainOf(ctx)
+	return context.WithValue(ctx, chainKey{}, append(chain[:len(chain):len(chain)], e))
+}
+
+func formatChain(chain []entry) string {
+	names := make([]string, 0, len(chain))
+	for _, e := range chain {
+		names = append(names, e.String())
+	}
 
+	return strings.Join(names, " -> ")
+}
+
+func (e entry) String() string {
+	if e.key == "" {
+		return e.t.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", e.t, e.key)
+}
+
+// resolveProvider 使用 owner 中绑定的 f 创建 t 的实例. 单例在 owner 中创建和缓存,
+// Scoped 的实例只能在作用域中创建并缓存在该作用域, 其它实例在当前容器中创建
+func (c *Container) resolveProvider(ctx context.Context, t reflect.Type, key string, f *provider, owner *Container) (result reflect.Value, err error) {
+	switch {
+	case f.Scoped && !c.isScope():
+		err = errx.New(fmt.Sprintf("type <%s> is scoped, resolve it from a scope", t.String()))
+		return
+	case !f.Scoped && f.Singleton && owner != c:
+		// 单例在绑定它的容器中创建, 不会持有作用域中的实例
+		return owner.resolveProvider(ctx, t, key, f, owner)
+	}
+
+	if f.Singleton || f.Scoped {
+		l := c.buildLock(t, key)
+		l.Lock()
+		defer l.Unlock()
+		// 等待期间实例可能已被其他 goroutine 创建
+		var ok bool
+		if result, ok = c.instance(t, key); ok {
 			return
 		}
 	}
 
-	result, err = c.resolveSlow(ctx, t, opts)
+	ctx = enter(ctx, entry{t: t, key: key})
+	result, err = c.build(ctx, f, t)
 	if err != nil {
 		return
 	}
-	if !result.IsValid() {
-		err = errx.Wrap(ErrNotFound, fmt.Sprintf("type <%s>", t.String()))
+	c.track(f, result)
+	result, err = c.decorate(ctx, t, result)
+	if err != nil {
 		return
 	}
 
+	if f.Singleton || f.Scoped {
+		c.cache(t, key, result)
+	}
+
 	return
 }
 
-// resolveSlow 尝试对已有类型进行转换
+// resolveSlow 尝试对已有类型进行转换, 当前容器中没有时从父容器中查找
 func (c *Container) resolveSlow(ctx context.Context, t reflect.Type, opts *resolveOpts) (result reflect.Value, err error) {
-	chain := ctx.Value("chain").([]reflect.Type)
-
-	for typ, group := range c.instances {
-		if typ.ConvertibleTo(t) {
-			if item, ok := group[opts.Key]; ok {
-				result = item.Convert(t)
-				group, ok := c.instances[t]
-				if !ok {
-					group = make(map[string]reflect.Value)
-					c.instances[t] = group
+	for cur := c; cur != nil; cur = cur.parent {
+		var (
+			instance reflect.Value
+			p        *provider
+		)
+		cur.lock.RLock()
+		for typ, group := range cur.instances {
+			if item, ok := group[opts.Key]; ok && typ.ConvertibleTo(t) {
+				instance = item
+				break
+			}
+		}
+		if !instance.IsValid() {
+			for typ, group := range cur.providers {
+				if item, ok := group[opts.Key]; ok && typ.ConvertibleTo(t) {
+					p = item
+					break
 				}
-				group[opts.Key] = result
-				chain = append(chain, t)
-				ctx = context.WithValue(ctx, "chain", chain)
-				return
 			}
 		}
-	}
-
-	for typ, group := range c.providers {
-		if typ.ConvertibleTo(t) {
-			if provider, ok := group[opts.Key]; ok {
-				chain = append(chain, t)
-				ctx = context.WithValue(ctx, "chain", chain)
+		cur.lock.RUnlock()
 
-				result, err = c.invokeAndGetType(ctx, provider.Value, t)
-				if err != nil {
-					return
-				}
+		switch {
+		case instance.IsValid():
+			result = instance.Convert(t)
+			cur.cache(t, opts.Key, result)
+			return
+		case p != nil:
+			return c.resolveProvider(ctx, t, opts.Key, p, cur)
+		}
+	}
 
-				if provider.Singleton {
-					group, ok := c.instances[t]
-					if !ok {
-						group = make(map[string]reflect.Value)
-						c.instances[t] = group
-					}
-					group[opts.Key] = result
-				}
+	return
+}
 
-				return
-			}
+// build 调用 provider 创建 t 的实例
+func (c *Container) build(ctx context.Context, p *provider, t reflect.Type) (result reflect.Value, err error) {
+	if p.Auto {
+		result, err = c.construct(ctx, p.Type)
+		if err != nil {
+			return
 		}
+		return c.convert(t, result)
 	}
 
-	return
+	return c.invokeAndGetType(ctx, p.Value, t, WithKeys(p.Keys))
 }
 
-func (c *Container) invokeAndGetType(ctx context.Context, f reflect.Value, resultType reflect.Type) (result reflect.Value, err error) {
-	results, err := c.invoke(ctx, f)
+func (c *Container) invokeAndGetType(ctx context.Context, f reflect.Value, resultType reflect.Type, opts ...func(*invokeOpts)) (result reflect.Value, err error) {
+	results, err := c.invoke(ctx, f, opts...)
 	if err != nil {
 		return
 	}
@@ -307,9 +549,6 @@ type invokeOpts struct {
 	keys   map[int]string
 }
 
-var waits = make(map[reflect.Type][]reflect.Value)
-var waitsLock sync.Mutex
-
 func (c *Container) invoke(ctx context.Context, f reflect.Value, opts ...func(*invokeOpts)) (results []reflect.Value, err error) {
 	options := &invokeOpts{}
 	for _, opt := range opts {
@@ -317,21 +556,15 @@ func (c *Container) invoke(ctx context.Context, f reflect.Value, opts ...func(*i
 	}
 
 	p := make([]reflect.Value, 0, f.Type().NumIn())
-	ts := make([]reflect.Type, 0, f.Type().NumIn())
 	for i := 0; i < f.Type().NumIn(); i++ {
 		var param reflect.Value
-		if len(options.params) <= 0 {
+		if v, ok := options.params[i]; ok {
+			param = reflect.ValueOf(v)
+		} else {
 			param, err = c.resolve(ctx, f.Type().In(i), &resolveOpts{Key: options.keys[i]})
 			if err != nil {
 				return
 			}
-			if param.IsNil() {
-				ts = append(ts, f.Type().In(i))
-			}
-		} else {
-			if p, ok := options.params[i]; ok {
-				param = reflect.ValueOf(p)
-			}
 		}
 
 		p = append(p, param)
@@ -339,72 +572,44 @@ func (c *Container) invoke(ctx context.Context, f reflect.Value, opts ...func(*i
 
 	results = f.Call(p)
 
-	if len(results) > 0 && !results[len(results)-1].IsNil() {
+	if len(results) > 0 && !isNil(results[len(results)-1]) {
 		var ok bool
 		if err, ok = results[len(results)-1].Interface().(error); ok {
 			return
 		}
 	}
 
-	if len(waits) > 0 {
-		waitsLock.Lock()
-		for _, result := range results {
-			if targets, ok := waits[result.Type()]; ok {
-				for _, target := range targets {
-					target.Elem().Set(result)
-				}
-				delete(waits, result.Type())
-			}
-		}
-		waitsLock.Unlock()
-	}
-
-	if len(ts) > 0 {
-		waitsLock.Lock()
-		for _, result := range results {
-			if result.IsNil() {
-				continue
-			}
-			v := reflect.Indirect(result)
-			if v.Kind() != reflect.Struct {
-				continue
-			}
+	return
+}
 
-			for i := 0; i < v.NumField(); i++ {
-				field := v.Field(i)
-				for _, t := range ts {
-					if t == field.Type() {
-						target := reflect.NewAt(t, field.Addr().UnsafePointer())
-						targets, ok := waits[t]
-						if !ok {
-							targets = make([]reflect.Value, 0, 1)
-						}
-						targets = append(targets, target)
-						waits[t] = targets
-						break
-					}
-				}
-			}
-		}
-		waitsLock.Unlock()
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
 	}
-
-	return
 }
 
+// Invoke 调用 f, 参数从容器中解析. f 可以是 Keyed 返回的 KeyedFunc, opts 中的 WithKeys 优先
 func (c *Container) Invoke(f reflect.Value, opts ...func(*invokeOpts)) (results []reflect.Value, err error) {
+	f, keys := unwrapKeyed(f)
+	if keys != nil {
+		opts = append([]func(*invokeOpts){WithKeys(keys)}, opts...)
+	}
+
 	return c.invoke(context.Background(), f, opts...)
 }
 
 func (c *Container) Has(t reflect.Type) bool {
-	_, ok := c.instances[t]
-	if ok {
-		return true
-	}
-
-	_, ok = c.providers[t]
-	if ok {
-		return true
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		_, hasInstance := cur.instances[t]
+		_, hasProvider := cur.providers[t]
+		cur.lock.RUnlock()
+		if hasInstance || hasProvider {
+			return true
+		}
 	}
 
 	return false
@@ -8,6 +8,13 @@ import (
 )
 
 func TestContainer_BindAndResolve(t *testing.T) {
+	// 使用独立的默认容器, 避免绑定泄漏到其他测试
+	original := Default()
+	Set(NewContainer())
+	t.Cleanup(func() {
+		Set(original)
+	})
+
 	type Test struct {
 		A int
 	}
@@ -182,18 +189,9 @@ func TestContainer_BindAndResolve(t *testing.T) {
 		err = Bind[*Struct2](NewStruct2)
 		require.NoError(t, err)
 
-		s2, err := Resolve[*Struct2]()
-		require.NoError(t, err)
-		require.Equal(t, s2.A, 2)
-		require.Equal(t, s2.S1.A, 1)
-
-		s1, err := Resolve[*Struct1]()
-		require.NoError(t, err)
-		require.Equal(t, s1.A, 1)
-		require.Equal(t, s1.S2.A, 2)
-
-		require.Same(t, s1.S2, s2)
-		require.Same(t, s1, s2.S1)
+		_, err = Resolve[*Struct2]()
+		require.ErrorIs(t, err, ErrCircularDependency)
+		require.Contains(t, err.Error(), "*container.Struct2 -> *container.Struct1 -> *container.Struct2")
 	})
 
 	t.Run("cycleResolve2", func(t *testing.T) {
@@ -204,18 +202,12 @@ func TestContainer_BindAndResolve(t *testing.T) {
 		err = Bind[*Struct13](NewStruct13)
 		require.NoError(t, err)
 
-		s11, err := Resolve[*Struct11]()
-		require.NoError(t, err)
-
-		s12, err := Resolve[*Struct12]()
-		require.NoError(t, err)
+		_, err = Resolve[*Struct11]()
+		require.ErrorIs(t, err, ErrCircularDependency)
+		require.Contains(t, err.Error(), "*container.Struct11 -> *container.Struct12 -> *container.Struct13 -> *container.Struct11")
 
-		s13, err := Resolve[*Struct13]()
-		require.NoError(t, err)
-
-		require.Same(t, s11, s13.s11)
-		require.Same(t, s12, s11.s12)
-		require.Same(t, s13, s12.s13)
+		_, err = Resolve[*Struct13]()
+		require.ErrorIs(t, err, ErrCircularDependency)
 	})
 
 	t.Run("cycleResolve3", func(t *testing.T) {
@@ -226,21 +218,9 @@ func TestContainer_BindAndResolve(t *testing.T) {
 		err = Bind[*Struct113](NewStruct113)
 		require.NoError(t, err)
 
-		s111, err := Resolve[*Struct111]()
-		require.NoError(t, err)
-
-		s112, err := Resolve[*Struct112]()
-		require.NoError(t, err)
-
-		s113, err := Resolve[*Struct113]()
-		require.NoError(t, err)
-
-		require.Same(t, s111, s113.s111)
-		require.Same(t, s111, s112.s111)
-		require.Same(t, s112, s111.s112)
-		require.Same(t, s112, s113.s112)
-		require.Same(t, s113, s112.s113)
-		require.Same(t, s113, s111.s113)
+		_, err = Resolve[*Struct111]()
+		require.ErrorIs(t, err, ErrCircularDependency)
+		require.Contains(t, err.Error(), "*container.Struct111 -> *container.Struct112 -> *container.Struct111")
 	})
 
 	t.Run("cycleAndConvertResolve", func(t *testing.T) {
@@ -249,21 +229,9 @@ func TestContainer_BindAndResolve(t *testing.T) {
 		err = Bind[*Struct1112](NewStruct1112)
 		require.NoError(t, err)
 
-		s1111, err := Resolve[*Struct1111]()
-		require.NoError(t, err)
-
-		s1112, err := Resolve[*Struct1112]()
-		require.NoError(t, err)
-
-		s1113, err := Resolve[*Struct1113]()
-		require.NoError(t, err)
-
-		require.NotSame(t, s1112, s1113)
-		require.Same(t, s1111, s1112.s1111)
-		require.NotSame(t, s1112, s1111.s1113)
-		require.True(t, s1111.A)
-		require.True(t, s1112.B)
-		require.True(t, s1113.B)
+		_, err = Resolve[*Struct1111]()
+		require.ErrorIs(t, err, ErrCircularDependency)
+		require.Contains(t, err.Error(), "*container.Struct1111 -> *container.Struct1113 -> *container.Struct1111")
 	})
 
 	t.Run("resolveKey", func(t *testing.T) {
@@ -0,0 +1,106 @@
+// Package containertest 提供在测试中隔离容器和替换绑定的工具, 测试结束时通过 t.Cleanup 自动恢复
+package containertest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zeddy-go/zeddy/container"
+)
+
+// New 创建独立的容器, 测试结束时关闭
+func New(t testing.TB) *container.Container {
+	t.Helper()
+
+	c := container.NewContainer()
+	t.Cleanup(func() {
+		closeContainer(t, c)
+	})
+
+	return c
+}
+
+// Clone 复制 c, 测试中对副本的修改不影响 c, 测试结束时关闭副本
+func Clone(t testing.TB, c *container.Container) *container.Container {
+	t.Helper()
+
+	clone := c.Clone()
+	t.Cleanup(func() {
+		closeContainer(t, clone)
+	})
+
+	return clone
+}
+
+// Isolate 将默认容器替换为它的副本并返回副本, 测试结束时恢复原来的默认容器
+func Isolate(t testing.TB) *container.Container {
+	t.Helper()
+
+	original := container.Default()
+	clone := original.Clone()
+	container.Set(clone)
+	t.Cleanup(func() {
+		container.Set(original)
+		closeContainer(t, clone)
+	})
+
+	return clone
+}
+
+// Snapshot 保存 c 当前的状态, 测试结束时恢复
+func Snapshot(t testing.TB, c *container.Container) {
+	t.Helper()
+
+	s := c.Snapshot()
+	t.Cleanup(func() {
+		c.Restore(s)
+	})
+}
+
+// Override 在测试期间将 c 中的 T 替换为 fake, fake 可以是实例或 provider.
+// 已创建的实例会被丢弃, 依赖 T 的实例在下次解析时使用 fake 重新创建, 测试结束时恢复原来的绑定和实例
+func Override[T any](t testing.TB, c *container.Container, fake any, opts ...container.BindOption) {
+	t.Helper()
+
+	Snapshot(t, c)
+	c.Reset()
+	if err := container.BindIn[T](c, fake, opts...); err != nil {
+		t.Fatalf("override %s: %s", reflect.TypeOf((*T)(nil)).Elem(), err)
+	}
+}
+
+// AssertResolvable 断言 c 中的每个绑定都可以解析, 解析在 c 的副本的作用域中进行, 结束后关闭副本
+func AssertResolvable(t testing.TB, c *container.Container) bool {
+	t.Helper()
+
+	clone := c.Clone()
+	scope := clone.NewScope()
+	defer closeContainer(t, clone)
+	defer closeContainer(t, scope)
+
+	ok := true
+	for _, b := range clone.Bindings() {
+		if _, err := scope.Resolve(b.Type, container.WithResolveKey(b.Key)); err != nil {
+			t.Errorf("resolve %s: %s", name(b), err)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+func name(b container.Binding) string {
+	if b.Key == "" {
+		return b.Type.String()
+	}
+
+	return b.Type.String() + "(" + b.Key + ")"
+}
+
+func closeContainer(t testing.TB, c *container.Container) {
+	t.Helper()
+
+	if err := c.Close(); err != nil {
+		t.Errorf("close container: %s", err)
+	}
+}
@@ -0,0 +1,110 @@
+package containertest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/container"
+)
+
+type store interface {
+	Get() string
+}
+
+type realStore struct{}
+
+func (realStore) Get() string { return "real" }
+
+type fakeStore struct{}
+
+func (fakeStore) Get() string { return "fake" }
+
+type service struct {
+	Store store `inject:""`
+}
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestOverride(t *testing.T) {
+	c := New(t)
+	require.NoError(t, container.BindIn[store](c, func() store { return realStore{} }))
+	require.NoError(t, container.AutowireIn[*service](c))
+
+	s, err := container.ResolveIn[*service](c)
+	require.NoError(t, err)
+	require.Equal(t, "real", s.Store.Get())
+
+	t.Run("override", func(t *testing.T) {
+		Override[store](t, c, fakeStore{})
+
+		s, err := container.ResolveIn[*service](c)
+		require.NoError(t, err)
+		require.Equal(t, "fake", s.Store.Get())
+	})
+
+	again, err := container.ResolveIn[*service](c)
+	require.NoError(t, err)
+	require.Same(t, s, again)
+	require.Equal(t, "real", again.Store.Get())
+}
+
+func TestIsolate(t *testing.T) {
+	original := container.Default()
+
+	t.Run("isolate", func(t *testing.T) {
+		c := Isolate(t)
+		require.Same(t, c, container.Default())
+		require.NoError(t, container.Bind[*fakeStore](&fakeStore{}))
+	})
+
+	require.Same(t, original, container.Default())
+	require.False(t, container.Has[*fakeStore]())
+}
+
+func TestSnapshotAndClone(t *testing.T) {
+	c := New(t)
+	require.NoError(t, container.BindIn[store](c, realStore{}))
+
+	clone := Clone(t, c)
+	require.NoError(t, container.BindIn[store](clone, fakeStore{}))
+
+	t.Run("snapshot", func(t *testing.T) {
+		Snapshot(t, c)
+		require.NoError(t, container.BindIn[*service](c, &service{}))
+		require.True(t, container.HasIn[*service](c))
+	})
+
+	require.False(t, container.HasIn[*service](c))
+	s, err := container.ResolveIn[store](c)
+	require.NoError(t, err)
+	require.Equal(t, "real", s.Get())
+	s, err = container.ResolveIn[store](clone)
+	require.NoError(t, err)
+	require.Equal(t, "fake", s.Get())
+}
+
+func TestAssertResolvable(t *testing.T) {
+	c := New(t)
+	require.NoError(t, container.BindIn[store](c, func() store { return realStore{} }))
+	require.NoError(t, container.BindIn[*service](c, func(s store) *service { return &service{Store: s} }, container.Scoped()))
+	require.True(t, AssertResolvable(t, c))
+
+	require.NoError(t, container.BindIn[*realStore](c, func() (*realStore, error) {
+		return nil, errors.New("connect failed")
+	}, container.WithKey("broken")))
+	r := &recorder{TB: t}
+	require.False(t, AssertResolvable(r, c))
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "resolve *containertest.realStore(broken): connect failed")
+}
@@ -0,0 +1,162 @@
+package container
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	LifetimeInstance  = "instance"
+	LifetimeSingleton = "singleton"
+	LifetimeScoped    = "scoped"
+	LifetimeTransient = "transient"
+)
+
+// Node 依赖图中的一个绑定
+type Node struct {
+	Type     string
+	Key      string
+	Lifetime string
+	// Provider provider 函数名, 直接绑定实例时为空
+	Provider string
+	Deps     []Dep
+}
+
+// Dep 依赖图中的一条边
+type Dep struct {
+	Type string
+	Key  string
+	// Missing 容器中没有对应的绑定
+	Missing bool
+}
+
+func (n Node) id() string {
+	return entryName(n.Type, n.Key)
+}
+
+func (d Dep) id() string {
+	return entryName(d.Type, d.Key)
+}
+
+func entryName(t string, key string) string {
+	if key == "" {
+		return t
+	}
+
+	return fmt.Sprintf("%s(%s)", t, key)
+}
+
+// Graph 容器的依赖图, 按类型和键排序
+type Graph []Node
+
+// Graph 返回容器中全部绑定组成的依赖图, 作用域中包含父容器的绑定
+func (c *Container) Graph() (g Graph) {
+	for _, b := range c.bindings() {
+		if b.p == nil {
+			g = append(g, Node{Type: b.t.String(), Key: b.key, Lifetime: LifetimeInstance})
+		} else {
+			g = append(g, c.providerNode(b.entry, b.p))
+		}
+	}
+	sort.Slice(g, func(i, j int) bool {
+		return g[i].id() < g[j].id()
+	})
+
+	return
+}
+
+func (c *Container) providerNode(e entry, p *provider) (n Node) {
+	n = Node{
+		Type:     e.t.String(),
+		Key:      e.key,
+		Lifetime: LifetimeTransient,
+	}
+	switch {
+	case p.Scoped:
+		n.Lifetime = LifetimeScoped
+	case p.Singleton:
+		n.Lifetime = LifetimeSingleton
+	}
+
+	if p.Auto {
+		n.Provider = "autowire"
+		st, _ := structType(p.Type)
+		for _, f := range injectFields(st) {
+			ft := st.Field(f.index).Type
+			n.Deps = append(n.Deps, Dep{
+				Type:    ft.String(),
+				Key:     f.key,
+				Missing: !f.optional && !c.canResolve(ft, f.key),
+			})
+		}
+		return
+	}
+
+	n.Provider = runtime.FuncForPC(p.Value.Pointer()).Name()
+	ft := p.Value.Type()
+	for i := 0; i < ft.NumIn(); i++ {
+		n.Deps = append(n.Deps, Dep{
+			Type:    ft.In(i).String(),
+			Key:     p.Keys[i],
+			Missing: !c.canResolve(ft.In(i), p.Keys[i]),
+		})
+	}
+
+	return
+}
+
+// WriteText 以文本形式输出依赖图, 每个绑定一行, 依赖缩进列在下方
+func (g Graph) WriteText(w io.Writer) (err error) {
+	for _, n := range g {
+		line := fmt.Sprintf("%s [%s]", n.id(), n.Lifetime)
+		if n.Provider != "" {
+			line += " <- " + n.Provider
+		}
+		if _, err = fmt.Fprintln(w, line); err != nil {
+			return
+		}
+		for _, d := range n.Deps {
+			line = "    -> " + d.id()
+			if d.Missing {
+				line += " (missing)"
+			}
+			if _, err = fmt.Fprintln(w, line); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// WriteDOT 以 graphviz DOT 格式输出依赖图, 缺失的依赖以红色虚线节点表示
+func (g Graph) WriteDOT(w io.Writer) (err error) {
+	var b strings.Builder
+	b.WriteString("digraph container {\n")
+	b.WriteString("    node [shape=box];\n")
+	missing := make(map[string]bool)
+	for _, n := range g {
+		_, _ = fmt.Fprintf(&b, "    %q [label=%q];\n", n.id(), n.id()+"\n"+n.Lifetime)
+		for _, d := range n.Deps {
+			_, _ = fmt.Fprintf(&b, "    %q -> %q;\n", n.id(), d.id())
+			if d.Missing {
+				missing[d.id()] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(&b, "    %q [style=dashed, color=red];\n", name)
+	}
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return
+}
@@ -0,0 +1,43 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type graphConfig struct{}
+
+type graphRepo struct{}
+
+type graphService struct{}
+
+func newGraphRepo(*graphConfig) *graphRepo { return &graphRepo{} }
+
+func newGraphService(*graphRepo, *Struct4) *graphService { return &graphService{} }
+
+func TestGraph(t *testing.T) {
+	c := NewContainer()
+	require.NoError(t, BindIn[*graphConfig](c, &graphConfig{}))
+	require.NoError(t, BindIn[*graphRepo](c, newGraphRepo, Scoped()))
+	require.NoError(t, BindIn[*graphService](c, newGraphService, NoSingleton(), WithKey("api")))
+
+	g := c.Graph()
+	require.Len(t, g, 3)
+	require.Equal(t, Node{Type: "*container.graphConfig", Lifetime: LifetimeInstance}, g[0])
+	require.Equal(t, LifetimeScoped, g[1].Lifetime)
+	require.Equal(t, "github.com/zeddy-go/zeddy/container.newGraphRepo", g[1].Provider)
+	require.Equal(t, []Dep{{Type: "*container.graphConfig"}}, g[1].Deps)
+	require.Equal(t, "api", g[2].Key)
+	require.Equal(t, []Dep{{Type: "*container.graphRepo"}, {Type: "*container.Struct4", Missing: true}}, g[2].Deps)
+
+	var text strings.Builder
+	require.NoError(t, g.WriteText(&text))
+	require.Contains(t, text.String(), "*container.graphService(api) [transient] <- github.com/zeddy-go/zeddy/container.newGraphService\n    -> *container.graphRepo\n    -> *container.Struct4 (missing)\n")
+
+	var dot strings.Builder
+	require.NoError(t, g.WriteDOT(&dot))
+	require.Contains(t, dot.String(), `"*container.graphService(api)" -> "*container.graphRepo";`)
+	require.Contains(t, dot.String(), `"*container.Struct4" [style=dashed, color=red];`)
+}
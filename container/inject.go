@@ -0,0 +1,201 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// injectTag 需要注入的字段的标签, 形如 `inject:""`, `inject:"key"` 或 `inject:"key,optional"`.
+// optional 的字段在容器中没有对应的绑定时保持零值
+const injectTag = "inject"
+
+type injectField struct {
+	index    int
+	key      string
+	optional bool
+}
+
+// injectFields 返回结构体中带有 inject 标签的字段
+func injectFields(t reflect.Type) (fields []injectField) {
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(injectTag)
+		if !ok || tag == "-" {
+			continue
+		}
+
+		key, flags, _ := strings.Cut(tag, ",")
+		fields = append(fields, injectField{
+			index:    i,
+			key:      key,
+			optional: flags == "optional",
+		})
+	}
+
+	return
+}
+
+// structType 返回结构体或结构体指针对应的结构体类型
+func structType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t, t.Kind() == reflect.Struct
+}
+
+// bindAuto 绑定没有 provider 的结构体或结构体指针, 解析时由容器创建并注入字段
+func (c *Container) bindAuto(t reflect.Type, options *bindOpts) (err error) {
+	if _, ok := structType(t); !ok {
+		return errx.New(fmt.Sprintf("can not autowire <%s>, struct or pointer to struct only", t))
+	}
+
+	group, ok := c.providers[t]
+	if !ok {
+		group = make(map[string]*provider)
+		c.providers[t] = group
+	}
+	group[options.Key] = &provider{
+		Auto:      true,
+		Type:      t,
+		Singleton: options.Singleton,
+		Scoped:    options.Scoped,
+		Dispose:   options.Dispose,
+	}
+
+	return
+}
+
+// construct 创建 t 的零值并注入带有 inject 标签的字段
+func (c *Container) construct(ctx context.Context, t reflect.Type) (result reflect.Value, err error) {
+	if t.Kind() == reflect.Pointer {
+		result = reflect.New(t.Elem())
+		err = c.fill(ctx, result.Elem())
+		return
+	}
+
+	ptr := reflect.New(t)
+	err = c.fill(ctx, ptr.Elem())
+	result = ptr.Elem()
+	return
+}
+
+// Fill 注入 ptr 指向的结构体中带有 inject 标签的字段
+func (c *Container) Fill(ptr reflect.Value) (err error) {
+	if !ptr.IsValid() {
+		return errx.New("can not fill nil, pointer to struct only")
+	}
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return errx.New(fmt.Sprintf("can not fill <%s>, pointer to struct only", ptr.Type()))
+	}
+
+	return c.fill(context.Background(), ptr.Elem())
+}
+
+func (c *Container) fill(ctx context.Context, v reflect.Value) (err error) {
+	t := v.Type()
+	for _, f := range injectFields(t) {
+		field := v.Field(f.index)
+		ft := field.Type()
+		if f.optional && !c.canResolve(ft, f.key) {
+			continue
+		}
+
+		var value reflect.Value
+		value, err = c.resolve(ctx, ft, &resolveOpts{Key: f.key})
+		if err != nil {
+			return errx.Wrap(err, fmt.Sprintf("inject field <%s.%s>", t, t.Field(f.index).Name))
+		}
+
+		if !field.CanSet() {
+			// 未导出的字段
+			field = reflect.NewAt(ft, unsafe.Pointer(field.UnsafeAddr())).Elem()
+		}
+		field.Set(value)
+	}
+
+	return
+}
+
+// canResolve 判断 t 是否有可用的绑定, 不会创建实例
+func (c *Container) canResolve(t reflect.Type, key string) bool {
+	if value, p, _ := c.find(t, key); value.IsValid() || p != nil {
+		return true
+	}
+	if t.Kind() == reflect.Slice && key == "" && len(c.keysOf(t.Elem())) > 0 {
+		return true
+	}
+
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		found := false
+		for typ, group := range cur.instances {
+			if _, ok := group[key]; ok && typ.ConvertibleTo(t) {
+				found = true
+				break
+			}
+		}
+		for typ, group := range cur.providers {
+			if _, ok := group[key]; ok && typ.ConvertibleTo(t) {
+				found = true
+				break
+			}
+		}
+		cur.lock.RUnlock()
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keysOf 返回 t 在容器链中绑定的全部键, 按绑定顺序排列, 作用域中的绑定覆盖父容器中相同的键
+func (c *Container) keysOf(t reflect.Type) (keys []string) {
+	orders := make(map[string]uint64)
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		// 只包含显式绑定的键, 解析时缓存的转换结果不计入
+		for e, seq := range cur.order {
+			if _, ok := orders[e.key]; !ok && e.t == t {
+				orders[e.key] = seq
+			}
+		}
+		cur.lock.RUnlock()
+	}
+
+	keys = make([]string, 0, len(orders))
+	for key := range orders {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return orders[keys[i]] < orders[keys[j]]
+	})
+
+	return
+}
+
+// resolveAll 解析元素类型的全部绑定组成的切片, 元素类型没有绑定时返回无效值
+func (c *Container) resolveAll(ctx context.Context, t reflect.Type) (result reflect.Value, err error) {
+	keys := c.keysOf(t.Elem())
+	if len(keys) == 0 {
+		return
+	}
+
+	result = reflect.MakeSlice(t, 0, len(keys))
+	for _, key := range keys {
+		var item reflect.Value
+		item, err = c.resolve(ctx, t.Elem(), &resolveOpts{Key: key})
+		if err != nil {
+			return
+		}
+		result = reflect.Append(result, item)
+	}
+
+	return
+}
@@ -0,0 +1,121 @@
+package container
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type injectGreeter interface {
+	Greet() string
+}
+
+type injectHello struct{ name string }
+
+func (h injectHello) Greet() string { return "hello " + h.name }
+
+func TestInject(t *testing.T) {
+	type Config struct{ Name string }
+	type Logger struct{ Prefix string }
+	type Service struct {
+		Config   *Config `inject:""`
+		Primary  *Config `inject:"primary"`
+		Logger   *Logger `inject:",optional"`
+		logger   *Logger `inject:"named,optional"`
+		config   *Config `inject:""`
+		Untagged *Config
+	}
+
+	c := NewContainer()
+	require.NoError(t, BindIn[*Config](c, &Config{Name: "default"}))
+	require.NoError(t, BindIn[*Config](c, &Config{Name: "primary"}, WithKey("primary")))
+
+	t.Run("autowire", func(t *testing.T) {
+		require.NoError(t, AutowireIn[*Service](c))
+
+		s, err := ResolveIn[*Service](c)
+		require.NoError(t, err)
+		require.Equal(t, "default", s.Config.Name)
+		require.Equal(t, "primary", s.Primary.Name)
+		require.Same(t, s.Config, s.config)
+		require.Nil(t, s.Logger)
+		require.Nil(t, s.logger)
+		require.Nil(t, s.Untagged)
+
+		again, err := ResolveIn[*Service](c)
+		require.NoError(t, err)
+		require.Same(t, s, again)
+	})
+
+	t.Run("optional present", func(t *testing.T) {
+		s := c.NewScope()
+		require.NoError(t, BindIn[*Logger](s, &Logger{Prefix: "scope"}, WithKey("named")))
+
+		var service Service
+		require.NoError(t, FillIn(s, &service))
+		require.Nil(t, service.Logger)
+		require.Equal(t, "scope", service.logger.Prefix)
+	})
+
+	t.Run("struct value", func(t *testing.T) {
+		require.NoError(t, AutowireIn[Service](c, NoSingleton()))
+
+		s, err := ResolveIn[Service](c)
+		require.NoError(t, err)
+		require.Equal(t, "primary", s.Primary.Name)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		type Broken struct {
+			Logger *Logger `inject:""`
+		}
+		require.NoError(t, AutowireIn[*Broken](c))
+
+		_, err := ResolveIn[*Broken](c)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Contains(t, err.Error(), "inject field <container.Broken.Logger>")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.Error(t, AutowireIn[int](c))
+		require.Error(t, FillIn(c, Service{}))
+		require.Error(t, FillIn(c, nil))
+	})
+}
+
+func TestResolveAll(t *testing.T) {
+	c := NewContainer()
+
+	_, err := ResolveIn[[]injectGreeter](c)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, BindIn[injectGreeter](c, injectHello{name: "a"}, WithKey("a")))
+	require.NoError(t, BindIn[injectGreeter](c, func() injectGreeter {
+		return injectHello{name: "b"}
+	}, WithKey("b")))
+	require.NoError(t, BindIn[injectGreeter](c, injectHello{name: "default"}))
+
+	greeters, err := ResolveIn[[]injectGreeter](c)
+	require.NoError(t, err)
+	require.Len(t, greeters, 3)
+	require.Equal(t, "hello a", greeters[0].Greet())
+	require.Equal(t, "hello b", greeters[1].Greet())
+	require.Equal(t, "hello default", greeters[2].Greet())
+
+	// 作用域中的绑定覆盖相同的键
+	s := c.NewScope()
+	require.NoError(t, BindIn[injectGreeter](s, injectHello{name: "scope"}, WithKey("a")))
+	type Consumer struct {
+		Greeters []injectGreeter `inject:""`
+	}
+	var consumer Consumer
+	require.NoError(t, FillIn(s, &consumer))
+	require.Len(t, consumer.Greeters, 3)
+	require.Equal(t, "hello b", consumer.Greeters[0].Greet())
+	require.Equal(t, "hello scope", consumer.Greeters[2].Greet())
+
+	// 直接绑定的切片优先
+	require.NoError(t, BindIn[[]injectGreeter](c, []injectGreeter{injectHello{name: "only"}}))
+	greeters, err = ResolveIn[[]injectGreeter](c)
+	require.NoError(t, err)
+	require.Len(t, greeters, 1)
+}
@@ -0,0 +1,49 @@
+package container
+
+import "reflect"
+
+// KeyedFunc 指定了参数解析键的函数, 由 Keyed 创建
+type KeyedFunc struct {
+	Func any
+	// Keys 参数下标到键的映射
+	Keys map[int]string
+}
+
+var keyedFuncType = reflect.TypeOf(KeyedFunc{})
+
+// Keyed 指定 f 的参数解析时使用的键, keys 为参数下标到键的映射, 未指定的参数使用默认键.
+// 返回值可以作为 Bind 的 provider, Invoke 的函数或 ginx 的 handler 使用
+func Keyed(f any, keys map[int]string) KeyedFunc {
+	return KeyedFunc{Func: f, Keys: keys}
+}
+
+// WithKeys 指定参数解析时使用的键, keys 为参数下标到键的映射
+func WithKeys(keys map[int]string) func(*invokeOpts) {
+	return func(opts *invokeOpts) {
+		if opts.keys == nil {
+			opts.keys = make(map[int]string, len(keys))
+		}
+		for i, key := range keys {
+			opts.keys[i] = key
+		}
+	}
+}
+
+// UnwrapKeyed 返回 KeyedFunc 中的函数和参数的键, 其他值原样返回
+func UnwrapKeyed(f any) (fn any, keys map[int]string) {
+	if k, ok := f.(KeyedFunc); ok {
+		return k.Func, k.Keys
+	}
+
+	return f, nil
+}
+
+// unwrapKeyed 与 UnwrapKeyed 相同, 但用于 reflect.Value
+func unwrapKeyed(value reflect.Value) (fn reflect.Value, keys map[int]string) {
+	if value.IsValid() && value.Type() == keyedFuncType {
+		k := value.Interface().(KeyedFunc)
+		return reflect.ValueOf(k.Func), k.Keys
+	}
+
+	return value, nil
+}
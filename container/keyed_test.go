@@ -0,0 +1,45 @@
+package container
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKeyed(t *testing.T) {
+	type DB struct{ Name string }
+	type Service struct{ Primary, Analytics *DB }
+
+	c := NewContainer()
+	require.NoError(t, BindIn[*DB](c, &DB{Name: "primary"}, WithKey("primary")))
+	require.NoError(t, BindIn[*DB](c, &DB{Name: "analytics"}, WithKey("analytics")))
+	require.NoError(t, BindIn[*Service](c, Keyed(func(primary *DB, analytics *DB) *Service {
+		return &Service{Primary: primary, Analytics: analytics}
+	}, map[int]string{0: "primary", 1: "analytics"})))
+
+	t.Run("provider", func(t *testing.T) {
+		s, err := ResolveIn[*Service](c)
+		require.NoError(t, err)
+		require.Equal(t, "primary", s.Primary.Name)
+		require.Equal(t, "analytics", s.Analytics.Name)
+
+		g := c.Graph()
+		require.Equal(t, []Dep{{Type: "*container.DB", Key: "primary"}, {Type: "*container.DB", Key: "analytics"}}, g[len(g)-1].Deps)
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		var names []string
+		f := func(a *DB, b *DB) {
+			names = append(names, a.Name, b.Name)
+		}
+		require.NoError(t, InvokeIn(c, Keyed(f, map[int]string{0: "analytics", 1: "primary"})))
+		require.NoError(t, InvokeIn(c, f, WithKeys(map[int]string{0: "primary", 1: "primary"})))
+		// WithKeys 覆盖 Keyed 中相同下标的键
+		require.NoError(t, InvokeIn(c, Keyed(f, map[int]string{0: "analytics", 1: "analytics"}), WithKeys(map[int]string{1: "primary"})))
+		require.Equal(t, []string{"analytics", "primary", "primary", "primary", "analytics", "primary"}, names)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		err := InvokeIn(c, Keyed(func(*DB) {}, map[int]string{0: "report"}))
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,107 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// Disposer 实现该接口的单例和作用域实例在容器 Close 时自动调用 Dispose, 优先于 io.Closer
+type Disposer interface {
+	Dispose() error
+}
+
+// autoDispose 返回 value 实现的 Disposer 或 io.Closer 对应的释放函数, 都没有实现时返回无效值
+func autoDispose(value reflect.Value) reflect.Value {
+	if !value.IsValid() || isNil(value) {
+		return reflect.Value{}
+	}
+
+	switch value.Interface().(type) {
+	case Disposer, io.Closer:
+		return reflect.ValueOf(dispose)
+	default:
+		return reflect.Value{}
+	}
+}
+
+func dispose(v any) error {
+	switch x := v.(type) {
+	case Disposer:
+		return x.Dispose()
+	case io.Closer:
+		return x.Close()
+	default:
+		return nil
+	}
+}
+
+// OnResolve 注册 t 的装饰器, 形如 func(T, ...) T 或 func(T, ...) (T, error), 第一个参数之外的参数从容器中解析.
+// 装饰器在 provider 创建实例后按注册顺序执行, 父容器的装饰器先于作用域的执行, 单例和作用域实例只装饰一次.
+// 直接绑定的实例不会被装饰, 释放函数接收的是装饰前的实例
+func (c *Container) OnResolve(t reflect.Type, f reflect.Value) (err error) {
+	if err = checkDecorator(t, f); err != nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.decorators[t] = append(c.decorators[t], f)
+	return
+}
+
+func checkDecorator(t reflect.Type, f reflect.Value) error {
+	if !f.IsValid() || f.Kind() != reflect.Func {
+		return errx.New(fmt.Sprintf("decorator of <%s> should be a func", t))
+	}
+
+	ft := f.Type()
+	if ft.NumIn() < 1 || !t.AssignableTo(ft.In(0)) || ft.NumOut() < 1 || ft.NumOut() > 2 || !ft.Out(0).ConvertibleTo(t) ||
+		(ft.NumOut() == 2 && ft.Out(1) != reflect.TypeOf((*error)(nil)).Elem()) {
+		return errx.New(fmt.Sprintf("decorator of <%s> should be func(%s, ...) %s or func(%s, ...) (%s, error)", t, t, t, t, t))
+	}
+
+	return nil
+}
+
+// decorate 依次执行容器链中 t 的装饰器
+func (c *Container) decorate(ctx context.Context, t reflect.Type, value reflect.Value) (result reflect.Value, err error) {
+	var chain [][]reflect.Value
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		if decorators := cur.decorators[t]; len(decorators) > 0 {
+			chain = append(chain, decorators)
+		}
+		cur.lock.RUnlock()
+	}
+
+	result = value
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, f := range chain[i] {
+			var results []reflect.Value
+			results, err = c.invoke(ctx, f, WithParams(map[int]any{0: result.Interface()}))
+			if err != nil {
+				return
+			}
+
+			result = results[0]
+			if result.Kind() == reflect.Interface {
+				result = result.Elem()
+			}
+			if !result.IsValid() {
+				err = errx.New(fmt.Sprintf("decorator of <%s> returned nil", t))
+				return
+			}
+			result, err = c.convert(t, result)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,134 @@
+package container
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type lifecycleCloser struct {
+	name   string
+	events *[]string
+}
+
+func (l *lifecycleCloser) Close() error {
+	*l.events = append(*l.events, "close "+l.name)
+	return nil
+}
+
+type lifecycleDisposer struct {
+	lifecycleCloser
+}
+
+func (l *lifecycleDisposer) Dispose() error {
+	*l.events = append(*l.events, "dispose "+l.name)
+	return errors.New("dispose failed")
+}
+
+type lifecycleRepo interface {
+	Find() string
+}
+
+type lifecycleDBRepo struct{}
+
+func (lifecycleDBRepo) Find() string { return "db" }
+
+type lifecycleLoggedRepo struct {
+	prefix string
+	next   lifecycleRepo
+}
+
+func (l lifecycleLoggedRepo) Find() string { return l.prefix + "(" + l.next.Find() + ")" }
+
+func TestAutoDispose(t *testing.T) {
+	var events []string
+	c := NewContainer()
+	require.NoError(t, BindIn[*lifecycleCloser](c, func() *lifecycleCloser {
+		return &lifecycleCloser{name: "a", events: &events}
+	}))
+	require.NoError(t, BindIn[*lifecycleDisposer](c, func(*lifecycleCloser) *lifecycleDisposer {
+		return &lifecycleDisposer{lifecycleCloser{name: "b", events: &events}}
+	}))
+	require.NoError(t, BindIn[*lifecycleCloser](c, func() *lifecycleCloser {
+		return &lifecycleCloser{name: "transient", events: &events}
+	}, NoSingleton(), WithKey("transient")))
+	require.NoError(t, BindIn[*lifecycleCloser](c, &lifecycleCloser{name: "instance", events: &events}, WithKey("instance")))
+
+	_, err := ResolveIn[*lifecycleDisposer](c)
+	require.NoError(t, err)
+	_, err = ResolveIn[*lifecycleCloser](c, WithResolveKey("transient"))
+	require.NoError(t, err)
+	_, err = ResolveIn[*lifecycleCloser](c, WithResolveKey("instance"))
+	require.NoError(t, err)
+
+	err = c.Close()
+	require.ErrorContains(t, err, "dispose failed")
+	require.Equal(t, []string{"dispose b", "close a"}, events)
+
+	require.NoError(t, c.Close())
+	require.Len(t, events, 2)
+}
+
+func TestOnResolve(t *testing.T) {
+	type Prefix string
+
+	c := NewContainer()
+	require.NoError(t, BindIn[Prefix](c, Prefix("log")))
+	require.NoError(t, BindIn[lifecycleRepo](c, func() lifecycleRepo {
+		return lifecycleDBRepo{}
+	}))
+	require.NoError(t, BindIn[lifecycleRepo](c, func() lifecycleRepo {
+		return lifecycleDBRepo{}
+	}, NoSingleton(), WithKey("transient")))
+
+	calls := 0
+	require.NoError(t, OnResolveIn[lifecycleRepo](c, func(next lifecycleRepo, prefix Prefix) lifecycleRepo {
+		calls++
+		return lifecycleLoggedRepo{prefix: string(prefix), next: next}
+	}))
+	require.NoError(t, OnResolveIn[lifecycleRepo](c, func(next lifecycleRepo) (lifecycleRepo, error) {
+		return lifecycleLoggedRepo{prefix: "metrics", next: next}, nil
+	}))
+
+	repo, err := ResolveIn[lifecycleRepo](c)
+	require.NoError(t, err)
+	require.Equal(t, "metrics(log(db))", repo.Find())
+	_, err = ResolveIn[lifecycleRepo](c)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	for i := 0; i < 2; i++ {
+		_, err = ResolveIn[lifecycleRepo](c, WithResolveKey("transient"))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, calls)
+
+	t.Run("scope", func(t *testing.T) {
+		s := c.NewScope()
+		require.NoError(t, BindIn[lifecycleRepo](s, func() lifecycleRepo {
+			return lifecycleDBRepo{}
+		}, Scoped(), WithKey("scoped")))
+		require.NoError(t, OnResolveIn[lifecycleRepo](s, func(next lifecycleRepo) lifecycleRepo {
+			return lifecycleLoggedRepo{prefix: "scope", next: next}
+		}))
+
+		repo, err := ResolveIn[lifecycleRepo](s, WithResolveKey("scoped"))
+		require.NoError(t, err)
+		require.Equal(t, "scope(metrics(log(db)))", repo.Find())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		s := c.NewScope()
+		require.NoError(t, OnResolveIn[lifecycleRepo](s, func(next lifecycleRepo) (lifecycleRepo, error) {
+			return nil, errors.New("decorate failed")
+		}))
+		_, err := ResolveIn[lifecycleRepo](s, WithResolveKey("transient"))
+		require.ErrorContains(t, err, "decorate failed")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.Error(t, OnResolveIn[lifecycleRepo](c, func() lifecycleRepo { return nil }))
+		require.Error(t, OnResolveIn[lifecycleRepo](c, func(lifecycleRepo) int { return 0 }))
+		require.Error(t, OnResolveIn[lifecycleRepo](c, "not func"))
+	})
+}
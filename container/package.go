@@ -22,11 +22,51 @@ func Default() *Container {
 }
 
 func Bind[T any](providerOrInstance any, sets ...func(*bindOpts)) (err error) {
-	return Default().Bind(reflect.TypeOf((*T)(nil)).Elem(), reflect.ValueOf(providerOrInstance), sets...)
+	return BindIn[T](Default(), providerOrInstance, sets...)
+}
+
+// BindIn 与 Bind 相同, 但绑定到指定的容器
+func BindIn[T any](c *Container, providerOrInstance any, sets ...func(*bindOpts)) (err error) {
+	return c.Bind(reflect.TypeOf((*T)(nil)).Elem(), reflect.ValueOf(providerOrInstance), sets...)
+}
+
+// Autowire 绑定结构体或结构体指针 T, 解析时由容器创建 T 并注入带有 inject 标签的字段
+func Autowire[T any](sets ...func(*bindOpts)) (err error) {
+	return AutowireIn[T](Default(), sets...)
+}
+
+// AutowireIn 与 Autowire 相同, 但绑定到指定的容器
+func AutowireIn[T any](c *Container, sets ...func(*bindOpts)) (err error) {
+	return c.Bind(reflect.TypeOf((*T)(nil)).Elem(), reflect.Value{}, sets...)
+}
+
+// Fill 注入 ptr 指向的结构体中带有 inject 标签的字段
+func Fill(ptr any) (err error) {
+	return FillIn(Default(), ptr)
+}
+
+// FillIn 与 Fill 相同, 但使用指定的容器
+func FillIn(c *Container, ptr any) (err error) {
+	return c.Fill(reflect.ValueOf(ptr))
+}
+
+// OnResolve 注册 T 的装饰器, 详见 Container.OnResolve
+func OnResolve[T any](f any) (err error) {
+	return OnResolveIn[T](Default(), f)
+}
+
+// OnResolveIn 与 OnResolve 相同, 但注册到指定的容器
+func OnResolveIn[T any](c *Container, f any) (err error) {
+	return c.OnResolve(reflect.TypeOf((*T)(nil)).Elem(), reflect.ValueOf(f))
 }
 
 func Resolve[T any](opts ...func(*resolveOpts)) (result T, err error) {
-	res, err := Default().Resolve(reflect.TypeOf((*T)(nil)).Elem(), opts...)
+	return ResolveIn[T](Default(), opts...)
+}
+
+// ResolveIn 与 Resolve 相同, 但从指定的容器解析
+func ResolveIn[T any](c *Container, opts ...func(*resolveOpts)) (result T, err error) {
+	res, err := c.Resolve(reflect.TypeOf((*T)(nil)).Elem(), opts...)
 	if err != nil {
 		return
 	}
@@ -34,8 +74,12 @@ func Resolve[T any](opts ...func(*resolveOpts)) (result T, err error) {
 	return
 }
 
-func MustResolve[T any]() (result T) {
-	result, err := Resolve[T]()
+func MustResolve[T any](opts ...func(*resolveOpts)) (result T) {
+	return MustResolveIn[T](Default(), opts...)
+}
+
+func MustResolveIn[T any](c *Container, opts ...func(*resolveOpts)) (result T) {
+	result, err := ResolveIn[T](c, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -43,11 +87,20 @@ func MustResolve[T any]() (result T) {
 }
 
 func Has[T any]() bool {
-	return Default().Has(reflect.TypeOf((*T)(nil)).Elem())
+	return HasIn[T](Default())
+}
+
+func HasIn[T any](c *Container) bool {
+	return c.Has(reflect.TypeOf((*T)(nil)).Elem())
 }
 
 func Invoke(f any, opts ...func(*invokeOpts)) (err error) {
-	results, err := Default().Invoke(reflect.ValueOf(f), opts...)
+	return InvokeIn(Default(), f, opts...)
+}
+
+// InvokeIn 与 Invoke 相同, 但使用指定的容器解析参数
+func InvokeIn(c *Container, f any, opts ...func(*invokeOpts)) (err error) {
+	results, err := c.Invoke(reflect.ValueOf(f), opts...)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,125 @@
+package container
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	type Config struct{ Name string }
+	type UnitOfWork struct {
+		Config *Config
+		closed bool
+	}
+
+	c := NewContainer()
+	require.NoError(t, BindIn[*Config](c, &Config{Name: "root"}))
+	require.NoError(t, BindIn[*UnitOfWork](c, func(config *Config) *UnitOfWork {
+		return &UnitOfWork{Config: config}
+	}, Scoped(), OnDispose(func(u *UnitOfWork) {
+		u.closed = true
+	})))
+
+	t.Run("not in scope", func(t *testing.T) {
+		_, err := ResolveIn[*UnitOfWork](c)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is scoped")
+	})
+
+	t.Run("per scope", func(t *testing.T) {
+		s1 := c.NewScope()
+		s2 := c.NewScope()
+
+		u1, err := ResolveIn[*UnitOfWork](s1)
+		require.NoError(t, err)
+		u1Again, err := ResolveIn[*UnitOfWork](s1)
+		require.NoError(t, err)
+		require.Same(t, u1, u1Again)
+
+		u2, err := ResolveIn[*UnitOfWork](s2)
+		require.NoError(t, err)
+		require.NotSame(t, u1, u2)
+		require.Same(t, u1.Config, u2.Config)
+
+		require.NoError(t, s1.Close())
+		require.True(t, u1.closed)
+		require.False(t, u2.closed)
+	})
+
+	t.Run("bind in scope", func(t *testing.T) {
+		s := c.NewScope()
+		require.NoError(t, BindIn[*Config](s, &Config{Name: "scope"}))
+
+		u, err := ResolveIn[*UnitOfWork](s)
+		require.NoError(t, err)
+		require.Equal(t, "scope", u.Config.Name)
+
+		config, err := ResolveIn[*Config](c)
+		require.NoError(t, err)
+		require.Equal(t, "root", config.Name)
+	})
+
+	t.Run("provider in scope overrides resolved singleton", func(t *testing.T) {
+		type Clock struct{ Name string }
+		require.NoError(t, BindIn[*Clock](c, func() *Clock { return &Clock{Name: "root"} }))
+		root, err := ResolveIn[*Clock](c)
+		require.NoError(t, err)
+
+		s := c.NewScope()
+		require.NoError(t, BindIn[*Clock](s, func() *Clock { return &Clock{Name: "scope"} }))
+		clock, err := ResolveIn[*Clock](s)
+		require.NoError(t, err)
+		require.Equal(t, "scope", clock.Name)
+
+		again, err := ResolveIn[*Clock](c)
+		require.NoError(t, err)
+		require.Same(t, root, again)
+	})
+
+	t.Run("singleton does not capture scope", func(t *testing.T) {
+		type Service struct{ U *UnitOfWork }
+		require.NoError(t, BindIn[*Service](c, func(u *UnitOfWork) *Service {
+			return &Service{U: u}
+		}))
+
+		_, err := ResolveIn[*Service](c.NewScope())
+		require.Error(t, err)
+	})
+
+	t.Run("scoped through interface", func(t *testing.T) {
+		c := NewContainer()
+		require.NoError(t, BindIn[*scopedCounter](c, func() *scopedCounter {
+			return &scopedCounter{}
+		}, Scoped()))
+
+		_, err := ResolveIn[counter](c)
+		require.ErrorContains(t, err, "is scoped")
+
+		s1, s2 := c.NewScope(), c.NewScope()
+		c1, err := ResolveIn[counter](s1)
+		require.NoError(t, err)
+		c1Again, err := ResolveIn[counter](s1)
+		require.NoError(t, err)
+		require.Same(t, c1, c1Again)
+		c2, err := ResolveIn[counter](s2)
+		require.NoError(t, err)
+		require.NotSame(t, c1, c2)
+
+		_, err = ResolveIn[counter](c)
+		require.ErrorContains(t, err, "is scoped")
+	})
+
+	t.Run("invalid dispose", func(t *testing.T) {
+		require.Error(t, BindIn[*Config](c, &Config{}, OnDispose(func(string) {})))
+	})
+}
+
+type counter interface {
+	Count() int
+}
+
+type scopedCounter struct{ n int }
+
+func (s *scopedCounter) Count() int {
+	return s.n
+}
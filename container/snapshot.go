@@ -0,0 +1,151 @@
+package container
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// BindOption 绑定选项, 如 WithKey, NoSingleton, Scoped, OnDispose
+type BindOption = func(*bindOpts)
+
+// ResolveOption 解析选项, 如 WithResolveKey
+type ResolveOption = func(*resolveOpts)
+
+// Snapshot 容器在某一时刻的绑定、实例和装饰器, 由 Container.Snapshot 创建
+type Snapshot struct {
+	providers  map[reflect.Type]map[string]*provider
+	instances  map[reflect.Type]map[string]reflect.Value
+	order      map[entry]uint64
+	decorators map[reflect.Type][]reflect.Value
+}
+
+// Snapshot 保存容器当前的绑定、实例和装饰器, 可以通过 Restore 恢复. 不包含父容器和释放函数
+func (c *Container) Snapshot() *Snapshot {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return &Snapshot{
+		providers:  copyGroups(c.providers),
+		instances:  copyGroups(c.instances),
+		order:      copyMap(c.order),
+		decorators: copyDecorators(c.decorators),
+	}
+}
+
+// Restore 将容器恢复到 s 保存时的状态, 之后创建的实例不会被释放, 需要时先调用 Close
+func (c *Container) Restore(s *Snapshot) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.providers = copyGroups(s.providers)
+	c.instances = copyGroups(s.instances)
+	c.order = copyMap(s.order)
+	c.decorators = copyDecorators(s.decorators)
+	c.building = make(map[entry]*sync.Mutex)
+}
+
+// Clone 复制容器的绑定、实例和装饰器, 副本与原容器共享父容器, 之后互不影响. 释放函数不会被复制
+func (c *Container) Clone() *Container {
+	clone := NewContainer()
+	clone.parent = c.parent
+	clone.Restore(c.Snapshot())
+
+	return clone
+}
+
+// Reset 丢弃由 provider 创建并缓存的实例, 之后解析时重新创建, 直接绑定的实例保留
+func (c *Container) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for t, group := range c.instances {
+		for key := range group {
+			e := entry{t: t, key: key}
+			_, bound := c.order[e]
+			if !bound || c.providers[t][key] != nil {
+				delete(group, key)
+			}
+		}
+	}
+}
+
+// Binding 容器中的一个绑定
+type Binding struct {
+	Type reflect.Type
+	Key  string
+}
+
+// Bindings 返回容器中的全部绑定, 作用域中包含父容器的绑定, 按类型和键排序
+func (c *Container) Bindings() (bindings []Binding) {
+	for _, b := range c.bindings() {
+		bindings = append(bindings, Binding{Type: b.t, Key: b.key})
+	}
+
+	return
+}
+
+type binding struct {
+	entry
+	p *provider
+}
+
+// bindings 返回容器链中的 provider 和直接绑定的实例, 已创建的单例和作用域实例不单独列出
+func (c *Container) bindings() (bindings []binding) {
+	seen := make(map[entry]bool)
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		for t, group := range cur.providers {
+			for key, p := range group {
+				e := entry{t: t, key: key}
+				if !seen[e] {
+					seen[e] = true
+					bindings = append(bindings, binding{entry: e, p: p})
+				}
+			}
+		}
+		cur.lock.RUnlock()
+	}
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.lock.RLock()
+		for e := range cur.order {
+			if _, ok := cur.instances[e.t][e.key]; ok && !seen[e] {
+				seen[e] = true
+				bindings = append(bindings, binding{entry: e})
+			}
+		}
+		cur.lock.RUnlock()
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].String() < bindings[j].String()
+	})
+
+	return
+}
+
+func copyGroups[V any](groups map[reflect.Type]map[string]V) map[reflect.Type]map[string]V {
+	result := make(map[reflect.Type]map[string]V, len(groups))
+	for t, group := range groups {
+		result[t] = copyMap(group)
+	}
+
+	return result
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	result := make(map[K]V, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+
+	return result
+}
+
+func copyDecorators(decorators map[reflect.Type][]reflect.Value) map[reflect.Type][]reflect.Value {
+	result := make(map[reflect.Type][]reflect.Value, len(decorators))
+	for t, items := range decorators {
+		result[t] = append([]reflect.Value(nil), items...)
+	}
+
+	return result
+}
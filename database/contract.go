@@ -1,21 +1,27 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
+
+	"github.com/zeddy-go/zeddy/errx"
 )
 
+// ErrVersionConflict 乐观锁冲突, 记录已被其它操作修改
+var ErrVersionConflict = errx.New("version conflict", errx.WithCode(http.StatusConflict))
+
 type Migrator interface {
 	RegisterMigrates(...any) error
 	Migrate() error
 	Up(stepNum int) error
 	Down(stepNum int) error
+	Version() (version uint, dirty bool, err error)
 }
 
-type GoroutineTransaction interface {
-	Begin(sets ...func(*sql.TxOptions))
-	Commit() error
-	Rollback() error
-	Transaction(f func() error, sets ...func(*sql.TxOptions)) error
+// UnitOfWork 通过 context 传递事务, 嵌套调用使用保存点
+type UnitOfWork interface {
+	Transaction(ctx context.Context, f func(ctx context.Context) error, sets ...func(*sql.TxOptions)) error
 }
 
 type TransactionTx[T any] interface {
@@ -24,22 +30,53 @@ type TransactionTx[T any] interface {
 }
 
 type DBHolder[DB any] interface {
-	GetDB() DB
+	GetDB(ctx context.Context) DB
 }
 
+// Repository 仓库, 方法在 ctx 中有事务时使用该事务
 type Repository[Entity any] interface {
-	Create(...*Entity) error
-	Update(structOrMap any, conditions ...any) error
-	First(conditions ...any) (*Entity, error)
-	List(conditions ...any) ([]*Entity, error)
-	Delete(conditions ...any) error
-	Pagination(offset, limit int, conditions ...any) (total int64, list []*Entity, err error)
+	Create(ctx context.Context, entities ...*Entity) error
+	Upsert(ctx context.Context, conflict OnConflict, entities ...*Entity) error
+	Update(ctx context.Context, structOrMap any, conditions ...any) error
+	UpdateByIDs(ctx context.Context, ids any, structOrMap any) error
+	FirstOrCreate(ctx context.Context, entity *Entity, conditions ...any) (created bool, err error)
+	First(ctx context.Context, conditions ...any) (*Entity, error)
+	List(ctx context.Context, conditions ...any) ([]*Entity, error)
+	Delete(ctx context.Context, conditions ...any) error
+	ForceDelete(ctx context.Context, conditions ...any) error
+	Restore(ctx context.Context, conditions ...any) error
+	Count(ctx context.Context, conditions ...any) (int64, error)
+	Exists(ctx context.Context, conditions ...any) (bool, error)
+	Pluck(ctx context.Context, column string, dest any, conditions ...any) error
+	Sum(ctx context.Context, column string, dest any, conditions ...any) error
+	Max(ctx context.Context, column string, dest any, conditions ...any) error
+	Pagination(ctx context.Context, offset, limit int, conditions ...any) (total int64, list []*Entity, err error)
+	CursorPagination(ctx context.Context, page CursorPage, conditions ...any) (cursor Cursor, list []*Entity, err error)
+	Chunk(ctx context.Context, size int, f func(list []*Entity) error, conditions ...any) error
+	Each(ctx context.Context, size int, f func(entity *Entity) error, conditions ...any) error
 }
 
 type ConditionApplier[DB any] interface {
 	Apply(DB) (DB, error)
 }
 
+// OnConflict 创建时与已有记录冲突的处理方式. Columns 为冲突的唯一索引列, MySQL 不需要;
+// Updates 为冲突时更新的列, 为空时更新全部列; DoNothing 为 true 时忽略冲突的记录
+type OnConflict struct {
+	Columns   []string
+	Updates   []string
+	DoNothing bool
+}
+
 type Order []string
 
 type Condition []any
+
+// Trashed 作为查询条件时控制是否包含软删除的记录
+type Trashed int
+
+const (
+	WithoutTrashed Trashed = iota
+	WithTrashed
+	OnlyTrashed
+)
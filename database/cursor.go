@@ -0,0 +1,29 @@
+package database
+
+// Sort 排序字段, Field 为数据库列名
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+func Asc(field string) Sort {
+	return Sort{Field: field}
+}
+
+func Desc(field string) Sort {
+	return Sort{Field: field, Desc: true}
+}
+
+// CursorPage 游标分页参数. Cursor 为上一次返回的 Cursor.Next 或 Cursor.Prev, 为空时从第一页开始.
+// Sorts 必须能唯一确定行的顺序, 不包含主键时会追加主键升序
+type CursorPage struct {
+	Cursor string
+	Size   int
+	Sorts  []Sort
+}
+
+// Cursor 游标分页的结果, 没有下一页或上一页时对应的游标为空
+type Cursor struct {
+	Next string
+	Prev string
+}
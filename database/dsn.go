@@ -12,8 +12,10 @@ import (
 type Type string
 
 const (
-	TypeMysql    Type = "mysql"
-	TypePostgres Type = "postgres"
+	TypeMysql     Type = "mysql"
+	TypePostgres  Type = "postgres"
+	TypeSqlite    Type = "sqlite"
+	TypeSqlserver Type = "sqlserver"
 )
 
 type DSN string
@@ -5,10 +5,13 @@ import (
 	"github.com/zeddy-go/zeddy/container"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/schema"
 )
 
 var _ callbacks.BeforeCreateInterface = (*SnowflakeID)(nil)
 
+const snowflakeSettingKey = "zeddy:snowflake"
+
 type UnixTimestampMilli struct {
 	CreatedAt int64 `json:"created_at" gorm:"autoCreateTime:milli"`
 	UpdatedAt int64 `json:"updated_at" gorm:"autoUpdateTime:milli"`
@@ -20,17 +23,43 @@ type CommonField struct {
 	UpdatedAt int64 `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+// Version 乐观锁版本号, 详见 Repository.Update. 自定义的版本号字段可以使用 version 标签
+type Version struct {
+	Version int64 `json:"version" gorm:"not null;version"`
+}
+
+// versionField 返回 s 中带有 version 标签的字段
+func versionField(s *schema.Schema) *schema.Field {
+	for _, field := range s.Fields {
+		if _, ok := field.TagSettings["VERSION"]; ok && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
+
 type SnowflakeID struct {
 	ID uint64 `json:"id,string" gorm:"primaryKey;autoIncrement:false"`
 }
 
 func (s *SnowflakeID) BeforeCreate(tx *gorm.DB) (err error) {
-	snowflake, err := container.Resolve[*sonyflake.Sonyflake]()
-	if err != nil {
+	if s.ID != 0 {
 		return
 	}
-	if s.ID == 0 {
-		s.ID, err = snowflake.NextID()
+
+	var snowflake *sonyflake.Sonyflake
+	if tx != nil {
+		if v, ok := tx.Get(snowflakeSettingKey); ok {
+			snowflake, _ = v.(*sonyflake.Sonyflake)
+		}
+	}
+	if snowflake == nil {
+		snowflake, err = container.Resolve[*sonyflake.Sonyflake]()
+		if err != nil {
+			return
+		}
 	}
+
+	s.ID, err = snowflake.NextID()
 	return
 }
@@ -4,12 +4,15 @@ import (
 	"github.com/sony/sonyflake"
 	"github.com/stretchr/testify/require"
 	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/container/containertest"
 	"testing"
 )
 
 func TestSnowflakeID_BeforeCreate(t *testing.T) {
+	containertest.Isolate(t)
 	err := container.Bind[*sonyflake.Sonyflake](func() (*sonyflake.Sonyflake, error) {
-		return sonyflake.New(sonyflake.Settings{})
+		// 测试环境可能没有私有 ip, 固定机器 id
+		return sonyflake.New(sonyflake.Settings{MachineID: func() (uint16, error) { return 1, nil }})
 	})
 	require.NoError(t, err)
 	s := SnowflakeID{}
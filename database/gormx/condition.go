@@ -2,78 +2,152 @@ package gormx
 
 import (
 	"fmt"
-	"github.com/zeddy-go/zeddy/convert"
-	"github.com/zeddy-go/zeddy/database"
-	"reflect"
 	"strings"
 
+	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func contains(target []string, str string) bool {
-	for _, s := range target {
-		if strings.Contains(str, s) {
-			return true
+// applyCondition 将旧的 Condition 转换为 database.Expr 后应用
+func applyCondition(db *gorm.DB, conditions ...database.Condition) (newDB *gorm.DB, err error) {
+	exprs := make([]database.Expr, 0, len(conditions))
+	for _, c := range conditions {
+		var expr database.Expr
+		expr, err = c.Expr()
+		if err != nil {
+			return db, err
 		}
+		exprs = append(exprs, expr)
 	}
 
-	return false
+	return applyExpr(db, exprs...)
 }
 
-func quote(field string) string {
-	var arr []string
-	for _, item := range strings.Split(field, ".") {
-		arr = append(arr, "`"+item+"`")
+// applyExpr 编译 exprs 并以 AND 连接到 db 的 WHERE 中, 字段名由 db 的方言引用
+func applyExpr(db *gorm.DB, exprs ...database.Expr) (newDB *gorm.DB, err error) {
+	if len(exprs) == 0 {
+		return db, nil
 	}
-	return strings.Join(arr, ".")
-}
 
-func applyCondition(db *gorm.DB, conditions ...database.Condition) (newDB *gorm.DB, err error) {
-	newDB = db
-	for _, c := range conditions {
-		if len(c) < 2 {
-			return db, errx.New("condition require at least 2 params")
+	where := clause.Where{Exprs: make([]clause.Expression, 0, len(exprs))}
+	for _, expr := range exprs {
+		var e clause.Expression
+		e, err = compile(expr)
+		if err != nil {
+			return db, err
 		}
+		where.Exprs = append(where.Exprs, e)
+	}
 
-		list := []string{
-			" and ",
-			" or ",
-			"?",
-			" not ",
-			" between ",
-			" like ",
-			" is ",
-		}
-		if s, ok := c[0].(string); ok && contains(list, strings.ToLower(s)) {
-			newDB = newDB.Where(s, c[1:]...)
-		} else {
-			switch len(c) {
-			case 2:
-				v := reflect.ValueOf(c[1])
-				if v.Kind() == reflect.Slice {
-					newDB = newDB.Where(fmt.Sprintf("%s IN ?", quote(c[0].(string))), c[1])
-				} else {
-					newDB = newDB.Where(fmt.Sprintf("%s = ?", quote(c[0].(string))), c[1])
-				}
-			case 3:
-				switch c[1] {
-				case "like":
-					var value string
-					value, err = convert.To[string](c[2])
-					if err != nil {
-						return
-					}
-					newDB = db.Where(fmt.Sprintf("%s LIKE (?)", quote(c[0].(string))), "%"+value+"%")
-				default:
-					newDB = newDB.Where(fmt.Sprintf("%s %s (?)", quote(c[0].(string)), c[1]), c[2])
-				}
-			default:
-				err = errx.New("condition params is too many")
+	return db.Clauses(where), nil
+}
+
+func compile(expr database.Expr) (result clause.Expression, err error) {
+	switch x := expr.(type) {
+	case database.CompareExpr:
+		column := clause.Column{Name: x.Field}
+		switch x.Op {
+		case database.OpEq:
+			result = clause.Eq{Column: column, Value: x.Value}
+		case database.OpNe:
+			result = clause.Neq{Column: column, Value: x.Value}
+		case database.OpGt:
+			result = clause.Gt{Column: column, Value: x.Value}
+		case database.OpGte:
+			result = clause.Gte{Column: column, Value: x.Value}
+		case database.OpLt:
+			result = clause.Lt{Column: column, Value: x.Value}
+		case database.OpLte:
+			result = clause.Lte{Column: column, Value: x.Value}
+		default:
+			// 兼容旧写法的其它运算符
+			if !database.ValidOperator(x.Op) {
+				err = errx.New(fmt.Sprintf("unsupported compare operator: %s", x.Op))
 				return
 			}
+			result = clause.Expr{SQL: "? " + strings.ToUpper(x.Op) + " (?)", Vars: []any{column, x.Value}}
+		}
+	case database.InExpr:
+		result = clause.IN{Column: clause.Column{Name: x.Field}, Values: x.Values}
+	case database.BetweenExpr:
+		result = clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{clause.Column{Name: x.Field}, x.From, x.To}}
+	case database.LikeExpr:
+		result = clause.Like{Column: clause.Column{Name: x.Field}, Value: x.Pattern}
+	case database.NullExpr:
+		result = clause.Eq{Column: clause.Column{Name: x.Field}, Value: nil}
+	case database.NotExpr:
+		var e clause.Expression
+		e, err = compile(x.Expr)
+		if err != nil {
+			return
+		}
+		result = not{e}
+	case database.AndExpr:
+		var exprs []clause.Expression
+		exprs, err = compileAll(x.Exprs)
+		if err != nil {
+			return
+		}
+		result = join(exprs, clause.And)
+	case database.OrExpr:
+		var exprs []clause.Expression
+		exprs, err = compileAll(x.Exprs)
+		if err != nil {
+			return
 		}
+		result = join(exprs, clause.Or)
+	case database.RawExpr:
+		result = clause.Expr{SQL: x.SQL, Vars: x.Args}
+	case nil:
+		err = errx.New("condition expr is nil")
+	default:
+		err = errx.New(fmt.Sprintf("unsupported condition expr: %T", expr))
 	}
+	return
+}
 
+func compileAll(exprs []database.Expr) (result []clause.Expression, err error) {
+	if len(exprs) == 0 {
+		err = errx.New("condition group is empty")
+		return
+	}
+	result = make([]clause.Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		var e clause.Expression
+		e, err = compile(expr)
+		if err != nil {
+			return
+		}
+		result = append(result, e)
+	}
 	return
 }
+
+// join 只有一个条件时直接返回该条件, 多个条件时 gorm 会为分组加括号
+func join(exprs []clause.Expression, f func(...clause.Expression) clause.Expression) clause.Expression {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return f(exprs...)
+}
+
+// not 能取反的条件使用对应的运算符, 其它条件整体加括号后取反
+type not struct {
+	clause.Expression
+}
+
+func (n not) Build(builder clause.Builder) {
+	switch x := n.Expression.(type) {
+	case clause.NegationExpressionBuilder:
+		x.NegationBuild(builder)
+	case clause.AndConditions, clause.OrConditions:
+		builder.WriteString("NOT ")
+		x.Build(builder)
+	default:
+		builder.WriteString("NOT (")
+		x.Build(builder)
+		builder.WriteByte(')')
+	}
+}
@@ -25,7 +25,7 @@ func TestCondition(t *testing.T) {
 		db, err := applyCondition(db, []any{"id", ">", 1})
 		require.NoError(t, err)
 		db.Find(&testModel{})
-		require.Equal(t, "SELECT * FROM `test_models` WHERE `id` > (?)", db.Statement.SQL.String())
+		require.Equal(t, "SELECT * FROM `test_models` WHERE `id` > ?", db.Statement.SQL.String())
 		require.Equal(t, 1, db.Statement.Vars[0])
 	})
 
@@ -33,7 +33,7 @@ func TestCondition(t *testing.T) {
 		db, err := applyCondition(db, []any{"id", "like", "1"})
 		require.NoError(t, err)
 		db.Find(&testModel{})
-		require.Equal(t, "SELECT * FROM `test_models` WHERE `id` LIKE (?)", db.Statement.SQL.String())
+		require.Equal(t, "SELECT * FROM `test_models` WHERE `id` LIKE ?", db.Statement.SQL.String())
 		require.Equal(t, "%1%", db.Statement.Vars[0])
 	})
 
@@ -67,3 +67,84 @@ func TestCondition(t *testing.T) {
 		require.Equal(t, []any{1, "2", 1, "2"}, db.Statement.Vars)
 	})
 }
+
+func TestExpr(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{})
+	require.NoError(t, err)
+	db.DryRun = true
+	type testModel struct{}
+
+	cases := []struct {
+		name string
+		cond []any
+		sql  string
+		vars []any
+	}{
+		{
+			name: "like keeps previous conditions",
+			cond: []any{[]any{"id", 1}, []any{"name", "like", "a"}},
+			sql:  "SELECT * FROM `test_models` WHERE `id` = ? AND `name` LIKE ?",
+			vars: []any{1, "%a%"},
+		},
+		{
+			name: "typed",
+			cond: []any{
+				database.In("id", []int{1, 2}),
+				database.Between("age", 1, 10),
+				database.IsNull("t.deleted_at"),
+				database.Not(database.Like("name", "a%")),
+			},
+			sql:  "SELECT * FROM `test_models` WHERE `id` IN (?,?) AND (`age` BETWEEN ? AND ?) AND `t`.`deleted_at` IS NULL AND `name` NOT LIKE ?",
+			vars: []any{1, 2, 1, 10, "a%"},
+		},
+		{
+			name: "legacy not like",
+			cond: []any{[]any{"name", "not like", "a%"}},
+			sql:  "SELECT * FROM `test_models` WHERE `name` NOT LIKE ?",
+			vars: []any{"a%"},
+		},
+		{
+			name: "legacy operator",
+			cond: []any{[]any{"name", "regexp", "^a"}, []any{"email", "is not", nil}},
+			sql:  "SELECT * FROM `test_models` WHERE `name` REGEXP (?) AND `email` IS NOT NULL",
+			vars: []any{"^a"},
+		},
+		{
+			name: "nested",
+			cond: []any{
+				database.Eq("status", 1),
+				database.Or(
+					database.Gt("age", 18),
+					database.And(database.Eq("vip", true), database.NotNull("email")),
+				),
+			},
+			sql:  "SELECT * FROM `test_models` WHERE `status` = ? AND (`age` > ? OR (`vip` = ? AND `email` IS NOT NULL))",
+			vars: []any{1, 18, true},
+		},
+		{
+			name: "not group",
+			cond: []any{database.Not(database.Or(database.Eq("a", 1), database.Eq("b", 2)))},
+			sql:  "SELECT * FROM `test_models` WHERE NOT (`a` = ? OR `b` = ?)",
+			vars: []any{1, 2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, err := Apply(db, c.cond...)
+			require.NoError(t, err)
+			db.Find(&testModel{})
+			require.Equal(t, c.sql, db.Statement.SQL.String())
+			require.Equal(t, c.vars, db.Statement.Vars)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Apply(db, database.CompareExpr{Field: "id", Op: "; drop", Value: 1})
+		require.Error(t, err)
+		_, err = Apply(db, database.Or())
+		require.Error(t, err)
+		_, err = Apply(db, []any{"id", "= 1) or (1", 1})
+		require.Error(t, err)
+	})
+}
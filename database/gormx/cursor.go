@@ -0,0 +1,205 @@
+package gormx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/zeddy-go/zeddy/database"
+	"github.com/zeddy-go/zeddy/errx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// cursorToken 游标的内容, 编码为 base64 后作为不透明的字符串返回给调用方
+type cursorToken struct {
+	Sorts  string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+	Prev   bool              `json:"p,omitempty"`
+}
+
+func invalidCursor(err error) error {
+	if err == nil {
+		return errx.New("invalid cursor", errx.WithCode(http.StatusBadRequest))
+	}
+	return errx.Wrap(err, "invalid cursor", errx.WithCode(http.StatusBadRequest))
+}
+
+type sortField struct {
+	*schema.Field
+	desc bool
+}
+
+// resolveSorts 校验排序字段, 不包含主键时追加主键升序以保证顺序唯一
+func resolveSorts(s *schema.Schema, sorts []database.Sort) (fields []sortField, signature string, err error) {
+	hasPrimary := false
+	for _, sort := range sorts {
+		field := s.LookUpField(sort.Field)
+		if field == nil || field.DBName == "" {
+			err = errx.New(fmt.Sprintf("unknown sort field <%s> of <%s>", sort.Field, s.Name))
+			return
+		}
+		hasPrimary = hasPrimary || field.PrimaryKey
+		fields = append(fields, sortField{Field: field, desc: sort.Desc})
+	}
+	if !hasPrimary {
+		if s.PrioritizedPrimaryField == nil {
+			err = errx.New(fmt.Sprintf("<%s> has no primary key, sorts must be unique", s.Name))
+			return
+		}
+		fields = append(fields, sortField{Field: s.PrioritizedPrimaryField})
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.desc {
+			parts = append(parts, "-"+field.DBName)
+		} else {
+			parts = append(parts, field.DBName)
+		}
+	}
+	signature = strings.Join(parts, ",")
+	return
+}
+
+func encodeCursor(ctx context.Context, fields []sortField, signature string, row reflect.Value, prev bool) (cursor string, err error) {
+	token := cursorToken{Sorts: signature, Prev: prev, Values: make([]json.RawMessage, 0, len(fields))}
+	for _, field := range fields {
+		value, _ := field.ValueOf(ctx, row)
+		var raw []byte
+		raw, err = json.Marshal(value)
+		if err != nil {
+			return
+		}
+		token.Values = append(token.Values, raw)
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+	cursor = base64.RawURLEncoding.EncodeToString(data)
+	return
+}
+
+// decodeCursor 解析游标, 值按字段类型解码, 保证与数据库中的值类型一致
+func decodeCursor(cursor string, fields []sortField, signature string) (values []any, prev bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		err = invalidCursor(err)
+		return
+	}
+	var token cursorToken
+	if err = json.Unmarshal(data, &token); err != nil {
+		err = invalidCursor(err)
+		return
+	}
+	if token.Sorts != signature || len(token.Values) != len(fields) {
+		err = invalidCursor(nil)
+		return
+	}
+
+	values = make([]any, 0, len(fields))
+	for i, field := range fields {
+		v := reflect.New(field.FieldType)
+		if err = json.Unmarshal(token.Values[i], v.Interface()); err != nil {
+			err = invalidCursor(err)
+			return
+		}
+		values = append(values, v.Elem().Interface())
+	}
+	prev = token.Prev
+	return
+}
+
+// keyset 生成位于 values 之后(backward 时为之前)的条件:
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func keyset(fields []sortField, values []any, backward bool) database.Expr {
+	ors := make([]database.Expr, 0, len(fields))
+	for i, field := range fields {
+		ands := make([]database.Expr, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, database.Eq(fields[j].DBName, values[j]))
+		}
+		if field.desc != backward {
+			ands = append(ands, database.Lt(field.DBName, values[i]))
+		} else {
+			ands = append(ands, database.Gt(field.DBName, values[i]))
+		}
+		ors = append(ors, database.And(ands...))
+	}
+	return database.Or(ors...)
+}
+
+// cursorQuery 按 page 查询一页 PO, 多查询一行用于判断是否还有更多数据.
+// 排序字段的值不能为 NULL, 否则会跳过对应的行
+func cursorQuery[PO any](ctx context.Context, db *gorm.DB, page database.CursorPage) (cursor database.Cursor, list []PO, err error) {
+	if page.Size <= 0 {
+		err = errx.New("cursor page size must be positive")
+		return
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(new(PO)); err != nil {
+		return
+	}
+	fields, signature, err := resolveSorts(stmt.Schema, page.Sorts)
+	if err != nil {
+		return
+	}
+
+	var backward bool
+	if page.Cursor != "" {
+		var values []any
+		values, backward, err = decodeCursor(page.Cursor, fields, signature)
+		if err != nil {
+			return
+		}
+		db, err = applyExpr(db, keyset(fields, values, backward))
+		if err != nil {
+			return
+		}
+	}
+	for _, field := range fields {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: field.DBName}, Desc: field.desc != backward})
+	}
+
+	err = db.Limit(page.Size + 1).Find(&list).Error
+	if err != nil {
+		return
+	}
+
+	more := len(list) > page.Size
+	if more {
+		list = list[:page.Size]
+	}
+	if len(list) == 0 {
+		return
+	}
+	if backward {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
+
+	// 向前翻页时游标所在的行及之后一定还有数据
+	hasNext, hasPrev := more, page.Cursor != ""
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		cursor.Next, err = encodeCursor(ctx, fields, signature, reflect.ValueOf(&list[len(list)-1]).Elem(), false)
+		if err != nil {
+			return
+		}
+	}
+	if hasPrev {
+		cursor.Prev, err = encodeCursor(ctx, fields, signature, reflect.ValueOf(&list[0]).Elem(), true)
+	}
+	return
+}
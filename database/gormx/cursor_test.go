@@ -0,0 +1,129 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
+)
+
+type cursorItem struct {
+	ID        uint64
+	Score     int
+	CreatedAt time.Time
+}
+
+func TestCursorPagination(t *testing.T) {
+	holder := newTestHolder(t)
+	ctx := context.Background()
+	require.NoError(t, holder.GetDB(ctx).AutoMigrate(&cursorItem{}))
+	same := func(dst *cursorItem, src *cursorItem) error {
+		*dst = *src
+		return nil
+	}
+	repo := NewRepository[cursorItem, cursorItem](
+		WithDBHolder[cursorItem, cursorItem](holder),
+		WithM2E[cursorItem, cursorItem](same),
+		WithE2M[cursorItem, cursorItem](same),
+	)
+
+	now := time.Now()
+	var items []*cursorItem
+	for i := 1; i <= 7; i++ {
+		items = append(items, &cursorItem{Score: i % 3, CreatedAt: now.Add(time.Duration(i) * time.Second)})
+	}
+	require.NoError(t, repo.Create(ctx, items...))
+
+	ids := func(list []*cursorItem) (result []uint64) {
+		for _, item := range list {
+			result = append(result, item.ID)
+		}
+		return
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		// score: 1 2 0 1 2 0 1, 按 score 倒序, 相同时按 id 升序
+		page := database.CursorPage{Size: 3, Sorts: []database.Sort{database.Desc("score")}}
+		cursor, list, err := repo.CursorPagination(ctx, page)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{2, 5, 1}, ids(list))
+		require.Empty(t, cursor.Prev)
+		require.NotEmpty(t, cursor.Next)
+
+		page.Cursor = cursor.Next
+		cursor, list, err = repo.CursorPagination(ctx, page)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{4, 7, 3}, ids(list))
+		require.NotEmpty(t, cursor.Prev)
+
+		page.Cursor = cursor.Next
+		last, list, err := repo.CursorPagination(ctx, page)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{6}, ids(list))
+		require.Empty(t, last.Next)
+
+		page.Cursor = last.Prev
+		cursor, list, err = repo.CursorPagination(ctx, page)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{4, 7, 3}, ids(list))
+
+		page.Cursor = cursor.Prev
+		cursor, list, err = repo.CursorPagination(ctx, page)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{2, 5, 1}, ids(list))
+		require.Empty(t, cursor.Prev)
+		require.NotEmpty(t, cursor.Next)
+	})
+
+	t.Run("time and conditions", func(t *testing.T) {
+		page := database.CursorPage{Size: 2, Sorts: []database.Sort{database.Desc("created_at")}}
+		var got []uint64
+		for {
+			cursor, list, err := repo.CursorPagination(ctx, page, database.Ne("score", 0))
+			require.NoError(t, err)
+			got = append(got, ids(list)...)
+			if cursor.Next == "" {
+				break
+			}
+			page.Cursor = cursor.Next
+		}
+		require.Equal(t, []uint64{7, 5, 4, 2, 1}, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := repo.CursorPagination(ctx, database.CursorPage{Size: 2, Cursor: "bad"})
+		require.ErrorContains(t, err, "invalid cursor")
+
+		cursor, _, err := repo.CursorPagination(ctx, database.CursorPage{Size: 2})
+		require.NoError(t, err)
+		_, _, err = repo.CursorPagination(ctx, database.CursorPage{Size: 2, Cursor: cursor.Next, Sorts: []database.Sort{database.Asc("score")}})
+		require.ErrorContains(t, err, "invalid cursor")
+
+		_, _, err = repo.CursorPagination(ctx, database.CursorPage{Size: 2, Sorts: []database.Sort{database.Asc("unknown")}})
+		require.Error(t, err)
+	})
+
+	t.Run("chunk", func(t *testing.T) {
+		var sizes []int
+		require.NoError(t, repo.Chunk(ctx, 3, func(list []*cursorItem) error {
+			sizes = append(sizes, len(list))
+			return nil
+		}))
+		require.Equal(t, []int{3, 3, 1}, sizes)
+
+		var got []uint64
+		stop := errors.New("stop")
+		err := repo.Each(ctx, 2, func(item *cursorItem) error {
+			got = append(got, item.ID)
+			if len(got) == 3 {
+				return stop
+			}
+			return nil
+		}, []any{"score", ">", 0})
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, []uint64{1, 2, 4}, got)
+	})
+}
@@ -1,11 +1,11 @@
 package gormx
 
 import (
+	"context"
 	"database/sql"
-	"sync"
 
-	"github.com/timandy/routine"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 func WithIsolation(level sql.IsolationLevel) func(*sql.TxOptions) {
@@ -23,26 +23,32 @@ func WithReadOnly(readOnly bool) func(*sql.TxOptions) {
 func NewGormDBHolder(db *gorm.DB) *GormDBHolder {
 	return &GormDBHolder{
 		root: db,
-		txs:  make(map[int64]*gorm.DB),
 	}
 }
 
+// txKey 事务在 context 中的键, 每个 GormDBHolder 的事务互不影响
+type txKey struct {
+	holder *GormDBHolder
+}
+
+// GormDBHolder 持有连接, 事务通过 context 传递. 配置了副本时, 事务之外的读操作发送到副本, 写操作和事务使用主库
 type GormDBHolder struct {
 	root *gorm.DB
-	txs  map[int64]*gorm.DB
-	lock sync.Mutex
 }
 
-func (d *GormDBHolder) BeginTx(sets ...func(*sql.TxOptions)) (tx *gorm.DB) {
-	db := d.root.Session(&gorm.Session{
+func (d *GormDBHolder) session() *gorm.DB {
+	return d.root.Session(&gorm.Session{
 		SkipDefaultTransaction: true,
 		Logger:                 d.root.Logger,
 	})
+}
+
+func (d *GormDBHolder) BeginTx(sets ...func(*sql.TxOptions)) (tx *gorm.DB) {
 	opts := &sql.TxOptions{}
 	for _, set := range sets {
 		set(opts)
 	}
-	return db.Begin(opts)
+	return d.session().Clauses(dbresolver.Write).Begin(opts)
 }
 
 func (d *GormDBHolder) TransactionTx(f func(tx *gorm.DB) error, sets ...func(*sql.TxOptions)) (err error) {
@@ -58,67 +64,52 @@ func (d *GormDBHolder) TransactionTx(f func(tx *gorm.DB) error, sets ...func(*sq
 	return
 }
 
-func (d *GormDBHolder) Transaction(f func() error, sets ...func(*sql.TxOptions)) (err error) {
-	d.Begin(sets...)
-	err = f()
-	if err != nil {
-		d.Rollback()
-	} else {
-		err = d.Commit()
+// Transaction 在事务中执行 f, 事务通过传给 f 的 ctx 传递, 使用该 ctx 的仓库都在同一个事务中.
+// ctx 中已有事务时使用保存点嵌套, 此时 sets 不生效. f 返回错误或 panic 时回滚, panic 会继续向上传递
+func (d *GormDBHolder) Transaction(ctx context.Context, f func(ctx context.Context) error, sets ...func(*sql.TxOptions)) (err error) {
+	db := d.GetWriteDB(ctx)
+
+	var opts []*sql.TxOptions
+	if _, ok := txFrom(ctx, d); !ok {
+		o := &sql.TxOptions{}
+		for _, set := range sets {
+			set(o)
+		}
+		opts = append(opts, o)
 	}
 
-	return
+	return db.Transaction(func(tx *gorm.DB) error {
+		return f(context.WithValue(ctx, txKey{holder: d}, tx))
+	}, opts...)
 }
 
-func (d *GormDBHolder) Begin(sets ...func(*sql.TxOptions)) {
-	d.lock.Lock()
-	defer d.lock.Unlock()
-
-	db := d.get()
-	if db == nil {
-		db = d.BeginTx(sets...)
-		d.put(db)
+func txFrom(ctx context.Context, d *GormDBHolder) (tx *gorm.DB, ok bool) {
+	if ctx == nil {
+		return
 	}
+	tx, ok = ctx.Value(txKey{holder: d}).(*gorm.DB)
+	return
 }
 
-func (d *GormDBHolder) Commit() error {
-	d.lock.Lock()
-	defer func() {
-		delete(d.txs, routine.Goid())
-		d.lock.Unlock()
-	}()
-
-	w := d.get()
-	return w.Commit().Error
-}
-
-func (d *GormDBHolder) Rollback() error {
-	d.lock.Lock()
-	defer func() {
-		delete(d.txs, routine.Goid())
-		d.lock.Unlock()
-	}()
-
-	w := d.get()
-	return w.Rollback().Error
+// InTransaction 判断 ctx 中是否有该连接的事务
+func (d *GormDBHolder) InTransaction(ctx context.Context) bool {
+	_, ok := txFrom(ctx, d)
+	return ok
 }
 
-func (d *GormDBHolder) put(db *gorm.DB) {
-	d.txs[routine.Goid()] = db
-}
+// GetDB 返回 ctx 中的事务, 没有事务时返回新的会话, 都绑定了 ctx
+func (d *GormDBHolder) GetDB(ctx context.Context) (db *gorm.DB) {
+	if tx, ok := txFrom(ctx, d); ok {
+		return tx.WithContext(ctx)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-func (d *GormDBHolder) get() *gorm.DB {
-	return d.txs[routine.Goid()]
+	return d.session().WithContext(ctx)
 }
 
-func (d *GormDBHolder) GetDB() (db *gorm.DB) {
-	d.lock.Lock()
-	defer d.lock.Unlock()
-
-	db = d.get()
-	if db == nil {
-		db = d.root.Session(&gorm.Session{SkipDefaultTransaction: true, Logger: d.root.Logger})
-	}
-
-	return
+// GetWriteDB 返回写操作使用的连接, 在事务中时返回事务, 否则总是使用主库
+func (d *GormDBHolder) GetWriteDB(ctx context.Context) *gorm.DB {
+	return d.GetDB(ctx).Clauses(dbresolver.Write)
 }
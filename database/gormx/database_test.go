@@ -0,0 +1,94 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
+	"gorm.io/gorm"
+)
+
+type txUser struct {
+	ID   uint64
+	Name string
+}
+
+func newTestHolder(t *testing.T) *GormDBHolder {
+	dialector, err := Open(database.DSN("sqlite://" + filepath.Join(t.TempDir(), "test.db")))
+	require.NoError(t, err)
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&txUser{}))
+	t.Cleanup(func() {
+		require.NoError(t, closeGorm(db))
+	})
+
+	return NewGormDBHolder(db)
+}
+
+func TestTransaction(t *testing.T) {
+	holder := newTestHolder(t)
+	repo := NewRepository[txUser, txUser](WithDBHolder[txUser, txUser](holder))
+	ctx := context.Background()
+	count := func() int64 {
+		var n int64
+		require.NoError(t, holder.GetDB(ctx).Model(&txUser{}).Count(&n).Error)
+		return n
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		err := holder.Transaction(ctx, func(ctx context.Context) error {
+			require.True(t, holder.InTransaction(ctx))
+			require.NoError(t, repo.Create(ctx, &txUser{Name: "a"}))
+
+			// 事务随 ctx 跨 goroutine 传递
+			done := make(chan error)
+			go func() {
+				_, err := repo.First(ctx, []any{"name", "a"})
+				done <- err
+			}()
+			return <-done
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		err := holder.Transaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Create(ctx, &txUser{Name: "b"}))
+			return errors.New("failed")
+		})
+		require.EqualError(t, err, "failed")
+		require.Equal(t, int64(1), count())
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		err := holder.Transaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Create(ctx, &txUser{Name: "c"}))
+			err := holder.Transaction(ctx, func(ctx context.Context) error {
+				require.NoError(t, repo.Create(ctx, &txUser{Name: "d"}))
+				return errors.New("inner failed")
+			})
+			require.EqualError(t, err, "inner failed")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count())
+		_, err = repo.First(ctx, []any{"name", "d"})
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		require.Panics(t, func() {
+			_ = holder.Transaction(ctx, func(ctx context.Context) error {
+				require.NoError(t, repo.Create(ctx, &txUser{Name: "e"}))
+				panic("boom")
+			})
+		})
+		require.Equal(t, int64(2), count())
+		require.False(t, holder.InTransaction(ctx))
+	})
+}
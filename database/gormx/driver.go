@@ -0,0 +1,78 @@
+package gormx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/zeddy-go/zeddy/database"
+	"github.com/zeddy-go/zeddy/errx"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// Driver 根据 DSN 创建 gorm 的 Dialector
+type Driver func(dsn database.DSN) gorm.Dialector
+
+var (
+	drivers     = make(map[database.Type]Driver)
+	driversLock sync.RWMutex
+)
+
+func init() {
+	RegisterDriver(database.TypeMysql, func(dsn database.DSN) gorm.Dialector {
+		return mysql.Open(dsn.RemoveSchema())
+	})
+	// postgres 和 sqlserver 的驱动本身支持 url 形式的 DSN
+	RegisterDriver(database.TypePostgres, func(dsn database.DSN) gorm.Dialector {
+		return postgres.Open(string(dsn))
+	})
+	RegisterDriver(database.TypeSqlserver, func(dsn database.DSN) gorm.Dialector {
+		return sqlserver.Open(string(dsn))
+	})
+	// sqlite 的 DSN 为文件路径, 如 sqlite://data.db, sqlite:///tmp/data.db 或 sqlite://:memory:
+	RegisterDriver(database.TypeSqlite, func(dsn database.DSN) gorm.Dialector {
+		return sqlite.Open(strings.TrimPrefix(string(dsn), string(database.TypeSqlite)+"://"))
+	})
+}
+
+// RegisterDriver 注册 DSN schema 对应的驱动, 已注册的驱动会被替换
+func RegisterDriver(typ database.Type, driver Driver) {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+
+	drivers[typ] = driver
+}
+
+// Open 根据 DSN 的 schema 选择驱动并创建 Dialector
+func Open(dsn database.DSN) (dialector gorm.Dialector, err error) {
+	typ, err := dsnType(dsn)
+	if err != nil {
+		return
+	}
+
+	driversLock.RLock()
+	driver, ok := drivers[typ]
+	driversLock.RUnlock()
+	if !ok {
+		err = errx.New(fmt.Sprintf("database driver <%s> not registered", typ))
+		return
+	}
+
+	dialector = driver(dsn)
+	return
+}
+
+// dsnType 返回 DSN 的 schema, DSN.Type 在缺少 schema 时会 panic
+func dsnType(dsn database.DSN) (typ database.Type, err error) {
+	scheme, _, ok := strings.Cut(string(dsn), "://")
+	if !ok || scheme == "" {
+		err = errx.New("dsn is invalid, forget schema?")
+		return
+	}
+
+	return database.Type(scheme), nil
+}
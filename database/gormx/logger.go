@@ -0,0 +1,132 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeddy-go/zeddy/errx"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultSlowThreshold 默认的慢查询阈值, 可通过配置 slowThreshold 修改
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+func newLogger(slowThreshold time.Duration, level logger.LogLevel) logger.Interface {
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowThreshold
+	}
+
+	return &slogLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// parseLogLevel 解析 gorm 日志级别, 为空时返回 warn
+func parseLogLevel(level string) (logger.LogLevel, error) {
+	switch strings.ToLower(level) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn", "":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	default:
+		return 0, errx.New(fmt.Sprintf("invalid gorm log level <%s>", level))
+	}
+}
+
+// slogLogger 通过 slog 输出 gorm 日志, 普通 sql 为 debug 级别, 慢查询为 warn 级别, 出错的 sql 为 error 级别
+type slogLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	n := *l
+	n.level = level
+	return &n
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, logger.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "sql error", "error", err, "elapsed", elapsed, "rows", rows, "sql", sql)
+	case elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow sql", "threshold", l.slowThreshold, "elapsed", elapsed, "rows", rows, "sql", sql)
+	case l.level >= logger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "sql", "elapsed", elapsed, "rows", rows, "sql", sql)
+	}
+}
+
+func newSwappableLogger(l logger.Interface) *swappableLogger {
+	s := &swappableLogger{}
+	s.Swap(l)
+	return s
+}
+
+// swappableLogger 可以在运行时替换的 gorm 日志, 配置重新加载时替换内部的日志实例
+type swappableLogger struct {
+	v atomic.Pointer[logger.Interface]
+}
+
+func (s *swappableLogger) Swap(l logger.Interface) {
+	s.v.Store(&l)
+}
+
+func (s *swappableLogger) load() logger.Interface {
+	return *s.v.Load()
+}
+
+func (s *swappableLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return s.load().LogMode(level)
+}
+
+func (s *swappableLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	s.load().Info(ctx, msg, data...)
+}
+
+func (s *swappableLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	s.load().Warn(ctx, msg, data...)
+}
+
+func (s *swappableLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	s.load().Error(ctx, msg, data...)
+}
+
+func (s *swappableLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	s.load().Trace(ctx, begin, fc, err)
+}
@@ -1,23 +1,29 @@
-//go:build !sqlite
-
 package gormx
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"github.com/sony/sonyflake"
 	"github.com/spf13/viper"
 	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/configx"
 	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/database"
-	"gorm.io/driver/mysql"
+	"github.com/zeddy-go/zeddy/errx"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-	"log"
-	"os"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
+	"log/slog"
 	"sync"
 	"time"
 )
 
+// defaultPrefix 默认的配置前缀
+const defaultPrefix = "database"
+
+// WithPrefix 指定配置前缀, 与默认前缀不同时模块名称为 "gormx.<prefix>", 以便同一应用中使用多个实例
 func WithPrefix(prefix string) func(*Module) {
 	return func(module *Module) {
 		module.prefix = prefix
@@ -26,7 +32,7 @@ func WithPrefix(prefix string) func(*Module) {
 
 func NewModule(opts ...func(*Module)) *Module {
 	m := &Module{
-		prefix: "database",
+		prefix: defaultPrefix,
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -37,72 +43,244 @@ func NewModule(opts ...func(*Module)) *Module {
 type Module struct {
 	app.IsModule
 	prefix string
+	logger *swappableLogger
+	// dbs 已打开的连接, 用于统计连接池
+	dbs     map[string]*sql.DB
+	dbsLock sync.Mutex
+}
+
+// Name 使用默认前缀时为 "gormx", 否则为 "gormx.<prefix>"
+func (m *Module) Name() string {
+	if m.prefix == defaultPrefix {
+		return "gormx"
+	}
+
+	return "gormx." + m.prefix
+}
+
+func (m *Module) Dependencies() []string {
+	return []string{"configx"}
+}
+
+// Config 数据库配置, 顶层为默认连接, Connections 中为按名称绑定的其他连接
+type Config struct {
+	// DSN 根据 schema 选择驱动, 如 mysql://, postgres://, sqlite://, sqlserver://, 见 RegisterDriver.
+	// migrate 模块只内置了 mysql, postgres 和 sqlite 的迁移驱动, 使用 sqlserver 时需通过 migrate.RegisterDatabaseDriver 注册
+	DSN string `mapstructure:"dsn" validate:"required"`
+	// Replicas 只读副本的 DSN, 配置后读操作发送到副本, 写操作和事务使用主库
+	Replicas      []string      `mapstructure:"replicas"`
+	Pool          PoolConfig    `mapstructure:"pool"`
+	SlowThreshold time.Duration `mapstructure:"slowThreshold" default:"200ms"`
+	// LogLevel gorm 的日志级别, 可以是 silent, error, warn 或 info, 默认 warn 只记录慢查询和错误
+	LogLevel string `mapstructure:"logLevel" default:"warn" validate:"oneof=silent error warn info"`
+	// PrepareStmt 缓存预编译的语句
+	PrepareStmt bool `mapstructure:"prepareStmt"`
+	// SkipDefaultTransaction 单条写操作不再默认开启事务
+	SkipDefaultTransaction bool                        `mapstructure:"skipDefaultTransaction"`
+	Naming                 NamingConfig                `mapstructure:"naming"`
+	Connections            map[string]ConnectionConfig `mapstructure:"connections" validate:"dive"`
+}
+
+// ConnectionConfig 命名连接的配置, 通过 container.WithKey(name) 绑定, 其余 gorm 配置与默认连接相同
+type ConnectionConfig struct {
+	DSN      string   `mapstructure:"dsn" validate:"required"`
+	Replicas []string `mapstructure:"replicas"`
+	// Pool 未配置的项使用默认连接的配置
+	Pool PoolConfig `mapstructure:"pool"`
+}
+
+// NamingConfig 表名的命名策略
+type NamingConfig struct {
+	TablePrefix   string `mapstructure:"tablePrefix"`
+	SingularTable bool   `mapstructure:"singularTable"`
 }
 
-func (m *Module) newGorm(c *viper.Viper) (*gorm.DB, error) {
-	c = c.Sub(m.prefix)
-	dsn := database.DSN(c.GetString("dsn"))
-	return gorm.Open(mysql.Open(dsn.RemoveSchema()), &gorm.Config{
-		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
-			SlowThreshold:             200 * time.Millisecond,
-			LogLevel:                  logger.Warn,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  true,
-		}),
+// connection 返回名称对应的连接配置, 名称为空时返回默认连接
+func (c Config) connection(name string) (conn ConnectionConfig, err error) {
+	if name == "" {
+		return ConnectionConfig{DSN: c.DSN, Replicas: c.Replicas, Pool: c.Pool}, nil
+	}
+
+	conn, ok := c.Connections[name]
+	if !ok {
+		err = errx.New(fmt.Sprintf("database connection <%s> not configured", name))
+		return
+	}
+	conn.Pool = conn.Pool.inherit(c.Pool)
+	return
+}
+
+func (m *Module) newGorm(c ConnectionConfig, config Config, snowflake *sonyflake.Sonyflake) (db *gorm.DB, err error) {
+	dialector, err := Open(database.DSN(c.DSN))
+	if err != nil {
+		return
+	}
+	db, err = gorm.Open(dialector, &gorm.Config{
+		Logger:                 m.logger,
+		PrepareStmt:            config.PrepareStmt,
+		SkipDefaultTransaction: config.SkipDefaultTransaction,
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:   config.Naming.TablePrefix,
+			SingularTable: config.Naming.SingularTable,
+		},
 	})
+	if err != nil {
+		return
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	c.Pool.apply(sqlDB)
+
+	if len(c.Replicas) > 0 {
+		replicas := make([]gorm.Dialector, 0, len(c.Replicas))
+		for _, replica := range c.Replicas {
+			dialector, err = Open(database.DSN(replica))
+			if err != nil {
+				return
+			}
+			replicas = append(replicas, dialector)
+		}
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		})
+		c.Pool.applyResolver(resolver)
+		err = db.Use(resolver)
+		if err != nil {
+			return
+		}
+	}
+
+	// 将 snowflake 放入 db 的设置中, SnowflakeID 优先从这里获取, 从而不依赖默认容器
+	db = db.Set(snowflakeSettingKey, snowflake).Session(&gorm.Session{})
+	return
 }
 
 func (m *Module) Init() (err error) {
-	var (
-		gormInstance *gorm.DB
-		lock         sync.Mutex
-	)
-	err = container.Bind[*gorm.DB](func(c *viper.Viper) (db *gorm.DB, err error) {
-		lock.Lock()
-		defer lock.Unlock()
-		if gormInstance != nil {
-			db = gormInstance
+	err = configx.Bind[Config](m.Container(), m.prefix)
+	if err != nil {
+		return
+	}
+
+	err = container.BindIn[*Module](m.Container(), m)
+	if err != nil {
+		return
+	}
+
+	// 所有连接共享同一个日志, 只在这里根据配置创建, 之后由配置的订阅更新
+	m.logger = newSwappableLogger(newLogger(DefaultSlowThreshold, logger.Warn))
+	v, vErr := container.ResolveIn[*viper.Viper](m.Container())
+	if vErr == nil {
+		var l logger.Interface
+		l, err = m.loggerFrom(v)
+		if err != nil {
 			return
 		}
-		gormInstance, err = m.newGorm(c)
+		m.logger.Swap(l)
+	}
+	if cm, e := container.ResolveIn[*configx.Module](m.Container()); e == nil && vErr == nil {
+		refresh := func() {
+			l, e := m.loggerFrom(cm.Viper())
+			if e != nil {
+				slog.Error("refresh gorm logger failed", "error", e)
+				return
+			}
+			m.logger.Swap(l)
+		}
+		configx.Subscribe(cm, m.prefix+".slowThreshold", func(time.Duration) { refresh() })
+		configx.Subscribe(cm, m.prefix+".logLevel", func(string) { refresh() })
+	}
+
+	err = container.BindIn[*sonyflake.Sonyflake](m.Container(), func() *sonyflake.Sonyflake {
+		return sonyflake.NewSonyflake(sonyflake.Settings{})
+	})
+	if err != nil {
+		return
+	}
+
+	// 连接在 Init 时绑定, 此时配置还未校验, 只读取命名连接的名称
+	names := []string{""}
+	if vErr == nil {
+		for name := range v.GetStringMap(m.prefix + ".connections") {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		err = m.bindConnection(name)
 		if err != nil {
 			return
 		}
-		db = gormInstance
-		return
-	})
+	}
+
+	return
+}
+
+// loggerFrom 根据 v 中的 logLevel 和 slowThreshold 创建 gorm 的日志, 未配置时使用默认值
+func (m *Module) loggerFrom(v *viper.Viper) (l logger.Interface, err error) {
+	level, err := parseLogLevel(v.GetString(m.prefix + ".logLevel"))
 	if err != nil {
 		return
 	}
 
-	err = container.Bind[*sql.DB](func(c *viper.Viper) (db *sql.DB, err error) {
-		lock.Lock()
-		defer lock.Unlock()
+	return newLogger(v.GetDuration(m.prefix+".slowThreshold"), level), nil
+}
 
-		if gormInstance != nil {
-			return gormInstance.DB()
+// bindConnection 以 name 为键绑定连接的 *gorm.DB, *sql.DB 和 *GormDBHolder 并注册健康检查, 默认连接的 name 为空
+func (m *Module) bindConnection(name string) (err error) {
+	key := container.WithKey(name)
+	err = container.BindIn[*gorm.DB](m.Container(), func(c Config, snowflake *sonyflake.Sonyflake) (db *gorm.DB, err error) {
+		conn, err := c.connection(name)
+		if err != nil {
+			return
 		}
-		gormInstance, err = m.newGorm(c)
+		db, err = m.newGorm(conn, c, snowflake)
 		if err != nil {
 			return
 		}
-		return gormInstance.DB()
-	})
+		err = m.track(name, db)
+		return
+	}, key, container.OnDispose(closeGorm))
 	if err != nil {
 		return
 	}
 
-	err = container.Bind[*GormDBHolder](NewGormDBHolder)
+	keys := map[int]string{0: name}
+	err = container.BindIn[*sql.DB](m.Container(), container.Keyed(func(db *gorm.DB) (*sql.DB, error) {
+		return db.DB()
+	}, keys), key)
 	if err != nil {
 		return
 	}
 
-	err = container.Bind[*sonyflake.Sonyflake](func() *sonyflake.Sonyflake {
-		return sonyflake.NewSonyflake(sonyflake.Settings{})
-	})
+	err = container.BindIn[*GormDBHolder](m.Container(), container.Keyed(NewGormDBHolder, keys), key)
 	if err != nil {
 		return
 	}
 
+	check := m.Name()
+	if name != "" {
+		check += "." + name
+	}
+	m.App().Health().Register(check, func(ctx context.Context) (err error) {
+		db, err := container.ResolveIn[*sql.DB](m.Container(), container.WithResolveKey(name))
+		if err != nil {
+			return
+		}
+		return db.PingContext(ctx)
+	})
+
 	return
 }
+
+// closeGorm 关闭 db 底层的连接池, 重复关闭是安全的
+func closeGorm(db *gorm.DB) (err error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+
+	return sqlDB.Close()
+}
@@ -0,0 +1,84 @@
+package gormx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestConfigConnection(t *testing.T) {
+	c := Config{
+		DSN:      "mysql://root@tcp(primary)/app",
+		Replicas: []string{"mysql://root@tcp(replica)/app"},
+		Connections: map[string]ConnectionConfig{
+			"analytics": {DSN: "mysql://root@tcp(analytics)/app"},
+		},
+	}
+
+	conn, err := c.connection("")
+	require.NoError(t, err)
+	require.Equal(t, ConnectionConfig{DSN: c.DSN, Replicas: c.Replicas}, conn)
+
+	conn, err = c.connection("analytics")
+	require.NoError(t, err)
+	require.Equal(t, "mysql://root@tcp(analytics)/app", conn.DSN)
+
+	_, err = c.connection("report")
+	require.ErrorContains(t, err, "database connection <report> not configured")
+}
+
+func TestPoolConfig(t *testing.T) {
+	c := Config{
+		DSN:  "sqlite://file::memory:",
+		Pool: PoolConfig{MaxOpenConns: 10, MaxIdleConns: 5},
+		Connections: map[string]ConnectionConfig{
+			"analytics": {DSN: "sqlite://file::memory:", Pool: PoolConfig{MaxOpenConns: 3}},
+		},
+	}
+
+	conn, err := c.connection("analytics")
+	require.NoError(t, err)
+	require.Equal(t, PoolConfig{MaxOpenConns: 3, MaxIdleConns: 5}, conn.Pool)
+
+	m := NewModule()
+	m.logger = newSwappableLogger(newLogger(DefaultSlowThreshold, logger.Info))
+	db, err := m.newGorm(conn, c, nil)
+	require.NoError(t, err)
+	require.NoError(t, m.track("analytics", db))
+	require.Equal(t, 3, m.Stats()["analytics"].MaxOpenConnections)
+}
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := parseLogLevel("warn")
+	require.NoError(t, err)
+	require.Equal(t, logger.Warn, level)
+	level, err = parseLogLevel("")
+	require.NoError(t, err)
+	require.Equal(t, logger.Warn, level)
+	_, err = parseLogLevel("verbose")
+	require.Error(t, err)
+}
+
+func TestModuleName(t *testing.T) {
+	require.Equal(t, "gormx", NewModule().Name())
+	require.Equal(t, "gormx", NewModule(WithPrefix("database")).Name())
+	require.Equal(t, "gormx.other", NewModule(WithPrefix("other")).Name())
+}
+
+func TestNewGormKeepsLogger(t *testing.T) {
+	c := Config{DSN: "sqlite://file::memory:", LogLevel: "info"}
+	conn, err := c.connection("")
+	require.NoError(t, err)
+
+	m := NewModule()
+	m.logger = newSwappableLogger(newLogger(DefaultSlowThreshold, logger.Warn))
+	l := newLogger(time.Second, logger.Error)
+	m.logger.Swap(l)
+
+	db, err := m.newGorm(conn, c, nil)
+	require.NoError(t, err)
+	require.NoError(t, closeGorm(db))
+	require.Same(t, l, m.logger.load())
+}
@@ -0,0 +1,96 @@
+package gormx
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// PoolConfig 连接池配置, 为 0 的项使用 database/sql 的默认值
+type PoolConfig struct {
+	MaxOpenConns    int           `mapstructure:"maxOpenConns" validate:"gte=0"`
+	MaxIdleConns    int           `mapstructure:"maxIdleConns" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `mapstructure:"connMaxLifetime" validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `mapstructure:"connMaxIdleTime" validate:"gte=0"`
+}
+
+// inherit 未配置的项使用 parent 的配置
+func (p PoolConfig) inherit(parent PoolConfig) PoolConfig {
+	if p.MaxOpenConns == 0 {
+		p.MaxOpenConns = parent.MaxOpenConns
+	}
+	if p.MaxIdleConns == 0 {
+		p.MaxIdleConns = parent.MaxIdleConns
+	}
+	if p.ConnMaxLifetime == 0 {
+		p.ConnMaxLifetime = parent.ConnMaxLifetime
+	}
+	if p.ConnMaxIdleTime == 0 {
+		p.ConnMaxIdleTime = parent.ConnMaxIdleTime
+	}
+
+	return p
+}
+
+func (p PoolConfig) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// applyResolver 将连接池配置应用到副本
+func (p PoolConfig) applyResolver(resolver *dbresolver.DBResolver) {
+	if p.MaxOpenConns > 0 {
+		resolver.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		resolver.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		resolver.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		resolver.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// track 记录已打开的连接
+func (m *Module) track(name string, db *gorm.DB) (err error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+
+	m.dbsLock.Lock()
+	defer m.dbsLock.Unlock()
+	if m.dbs == nil {
+		m.dbs = make(map[string]*sql.DB)
+	}
+	m.dbs[name] = sqlDB
+
+	return
+}
+
+// Stats 返回已打开的连接的连接池统计, 可以定期上报为监控指标. 键为连接名称, 默认连接为空字符串
+func (m *Module) Stats() (stats map[string]sql.DBStats) {
+	m.dbsLock.Lock()
+	defer m.dbsLock.Unlock()
+
+	stats = make(map[string]sql.DBStats, len(m.dbs))
+	for name, db := range m.dbs {
+		stats[name] = db.Stats()
+	}
+
+	return
+}
@@ -1,13 +1,18 @@
 package gormx
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+
 	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
 	"github.com/zeddy-go/zeddy/mapper"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func WithM2E[PO any, Entity any](f func(dst *Entity, src *PO) error) func(*Repository[PO, Entity]) {
@@ -30,6 +35,25 @@ func WithE2M[PO any, Entity any](f func(dst *PO, src *Entity) error) func(*Repos
 	}
 }
 
+// WithDBHolder 指定仓库使用的 GormDBHolder, 不指定时从默认容器解析
+func WithDBHolder[PO any, Entity any](holder *GormDBHolder) func(*Repository[PO, Entity]) {
+	return func(r *Repository[PO, Entity]) {
+		r.GormDBHolder = holder
+	}
+}
+
+// WithConnection 返回使用命名连接的选项, 从 c 解析以 name 为键绑定的 GormDBHolder, 连接不存在时返回错误.
+// c 为创建仓库的容器, 如应用或请求作用域的容器
+func WithConnection[PO any, Entity any](c *container.Container, name string) (opt func(*Repository[PO, Entity]), err error) {
+	holder, err := container.ResolveIn[*GormDBHolder](c, container.WithResolveKey(name))
+	if err != nil {
+		err = errx.Wrap(err, fmt.Sprintf("resolve database connection <%s> failed", name))
+		return
+	}
+
+	return WithDBHolder[PO, Entity](holder), nil
+}
+
 func defaultM2E[PO any, Entity any](dst *Entity, src *PO) error {
 	return mapper.SimpleMap(dst, src)
 }
@@ -40,22 +64,51 @@ func defaultE2M[PO any, Entity any](dst *PO, src *Entity) error {
 
 func NewRepository[PO any, Entity any](opts ...func(*Repository[PO, Entity])) *Repository[PO, Entity] {
 	r := &Repository[PO, Entity]{
-		GormDBHolder: container.MustResolve[*GormDBHolder](),
-		m2e:          defaultM2E[PO, Entity],
-		e2m:          defaultE2M[PO, Entity],
+		m2e: defaultM2E[PO, Entity],
+		e2m: defaultE2M[PO, Entity],
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
+	if r.GormDBHolder == nil {
+		r.GormDBHolder = container.MustResolve[*GormDBHolder]()
+	}
+
 	return r
 }
 
+// Repository 仓库的方法从 ctx 中获取 GormDBHolder.Transaction 开启的事务
 type Repository[PO any, Entity any] struct {
 	*GormDBHolder
-	m2e func(dst *Entity, src *PO) error
-	e2m func(dst *PO, src *Entity) error
+	m2e     func(dst *Entity, src *PO) error
+	e2m     func(dst *PO, src *Entity) error
+	trashed database.Trashed
+}
+
+// WithTrashed 返回包含软删除记录的仓库副本
+func (r *Repository[PO, Entity]) WithTrashed() *Repository[PO, Entity] {
+	return r.withTrashed(database.WithTrashed)
+}
+
+// OnlyTrashed 返回只包含软删除记录的仓库副本
+func (r *Repository[PO, Entity]) OnlyTrashed() *Repository[PO, Entity] {
+	return r.withTrashed(database.OnlyTrashed)
+}
+
+func (r *Repository[PO, Entity]) withTrashed(trashed database.Trashed) *Repository[PO, Entity] {
+	c := *r
+	c.trashed = trashed
+	return &c
+}
+
+func (r *Repository[PO, Entity]) GetDB(ctx context.Context) *gorm.DB {
+	return applyTrashed(r.GormDBHolder.GetDB(ctx), r.trashed)
+}
+
+func (r *Repository[PO, Entity]) GetWriteDB(ctx context.Context) *gorm.DB {
+	return applyTrashed(r.GormDBHolder.GetWriteDB(ctx), r.trashed)
 }
 
 func (r *Repository[PO, Entity]) E2M(dst *PO, src *Entity) (err error) {
@@ -74,7 +127,32 @@ func (r *Repository[PO, Entity]) M2E(dst *Entity, src *PO) (err error) {
 	}
 }
 
-func (r *Repository[PO, Entity]) Create(entities ...*Entity) (err error) {
+func (r *Repository[PO, Entity]) Create(ctx context.Context, entities ...*Entity) (err error) {
+	return r.create(r.GetWriteDB(ctx), entities)
+}
+
+// Upsert 创建 entities, 与已有记录冲突时按 conflict 更新或忽略. 由 gorm 按方言生成 ON CONFLICT 或 ON DUPLICATE KEY 子句
+func (r *Repository[PO, Entity]) Upsert(ctx context.Context, conflict database.OnConflict, entities ...*Entity) (err error) {
+	onConflict := clause.OnConflict{DoNothing: conflict.DoNothing}
+	for _, column := range conflict.Columns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if !conflict.DoNothing {
+		if len(conflict.Updates) > 0 {
+			onConflict.DoUpdates = clause.AssignmentColumns(conflict.Updates)
+		} else {
+			onConflict.UpdateAll = true
+		}
+	}
+
+	return r.create(r.GetWriteDB(ctx).Clauses(onConflict), entities)
+}
+
+func (r *Repository[PO, Entity]) create(db *gorm.DB, entities []*Entity) (err error) {
+	if len(entities) == 0 {
+		return
+	}
+
 	pos := make([]*PO, 0, len(entities))
 	for _, item := range entities {
 		po := new(PO)
@@ -85,7 +163,7 @@ func (r *Repository[PO, Entity]) Create(entities ...*Entity) (err error) {
 		pos = append(pos, po)
 	}
 
-	err = r.GetDB().Create(&pos).Error
+	err = db.Create(&pos).Error
 	if err != nil {
 		return
 	}
@@ -99,8 +177,9 @@ func (r *Repository[PO, Entity]) Create(entities ...*Entity) (err error) {
 	return
 }
 
-// Update struct or map
-func (r *Repository[PO, Entity]) Update(entity any, conditions ...any) (err error) {
+// Update struct or map. 更新 *Entity 时, PO 包含带有 version 标签的字段(如 Version)则使用乐观锁:
+// 只更新版本号与 entity 相同的记录并将版本号加一, 记录已被修改或不存在时返回 database.ErrVersionConflict
+func (r *Repository[PO, Entity]) Update(ctx context.Context, entity any, conditions ...any) (err error) {
 	switch x := entity.(type) {
 	case *Entity:
 		po := new(PO)
@@ -108,7 +187,7 @@ func (r *Repository[PO, Entity]) Update(entity any, conditions ...any) (err erro
 		if err != nil {
 			return
 		}
-		err = r.GetDB().Updates(po).Error
+		err = updateVersioned(ctx, r.GetWriteDB(ctx), po)
 		if err != nil {
 			return
 		}
@@ -117,7 +196,7 @@ func (r *Repository[PO, Entity]) Update(entity any, conditions ...any) (err erro
 			return
 		}
 	case map[string]any:
-		query := r.GetDB()
+		query := r.GetWriteDB(ctx)
 		if len(conditions) > 0 {
 			query, err = Apply(query, conditions...)
 			if err != nil {
@@ -132,8 +211,115 @@ func (r *Repository[PO, Entity]) Update(entity any, conditions ...any) (err erro
 	return
 }
 
-func (r *Repository[PO, Entity]) Delete(conditions ...any) (err error) {
-	db, err := Apply(r.GetDB(), conditions...)
+func updateVersioned[PO any](ctx context.Context, db *gorm.DB, po *PO) (err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(po); err != nil {
+		return
+	}
+	field := versionField(stmt.Schema)
+	if field == nil {
+		return db.Updates(po).Error
+	}
+
+	// 只按版本号更新会修改该版本的全部记录, 必须同时按主键更新
+	rv := reflect.ValueOf(po).Elem()
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+	where := make([]clause.Expression, 0, len(stmt.Schema.PrimaryFields)+1)
+	for _, primary := range stmt.Schema.PrimaryFields {
+		pk, zero := primary.ValueOf(ctx, rv)
+		if zero {
+			return gorm.ErrMissingWhereClause
+		}
+		where = append(where, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: primary.DBName}, Value: pk})
+	}
+
+	value, _ := field.ValueOf(ctx, rv)
+	current := reflect.ValueOf(value)
+	next := reflect.New(current.Type()).Elem()
+	switch {
+	case current.CanInt():
+		next.SetInt(current.Int() + 1)
+	case current.CanUint():
+		next.SetUint(current.Uint() + 1)
+	default:
+		return errx.New(fmt.Sprintf("version field <%s.%s> must be integer", stmt.Schema.Name, field.Name))
+	}
+	where = append(where, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+
+	// 更新失败或冲突时恢复版本号, 保证与数据库中的一致
+	if err = field.Set(ctx, rv, next.Interface()); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = field.Set(ctx, rv, value)
+		}
+	}()
+
+	result := db.Model(po).Clauses(clause.Where{Exprs: where}).Updates(po)
+	if result.Error == nil && result.RowsAffected == 0 {
+		err = errx.Wrap(database.ErrVersionConflict, fmt.Sprintf("update <%s> with version %v", stmt.Schema.Table, value), errx.WithCode(http.StatusConflict))
+	} else {
+		err = result.Error
+	}
+	return
+}
+
+// UpdateByIDs 更新主键在 ids 中的记录, ids 为主键的切片, 为空时不做任何操作. values 可以是 *Entity 或 map,
+// 使用 *Entity 时只更新非零值字段, 忽略主键和版本字段
+func (r *Repository[PO, Entity]) UpdateByIDs(ctx context.Context, ids any, values any) (err error) {
+	v := reflect.ValueOf(ids)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return errx.New(fmt.Sprintf("ids must be slice, got %T", ids))
+	}
+	if v.Len() == 0 {
+		return
+	}
+
+	db := r.GetWriteDB(ctx).Model(new(PO)).Where(ids)
+	switch x := values.(type) {
+	case *Entity:
+		po := new(PO)
+		err = r.E2M(po, x)
+		if err != nil {
+			return
+		}
+		// 主键和版本字段不更新, 版本号也不会被检查或递增
+		stmt := &gorm.Statement{DB: db}
+		if err = stmt.Parse(po); err != nil {
+			return
+		}
+		omits := make([]string, 0, len(stmt.Schema.PrimaryFieldDBNames)+1)
+		omits = append(omits, stmt.Schema.PrimaryFieldDBNames...)
+		if field := versionField(stmt.Schema); field != nil {
+			omits = append(omits, field.DBName)
+		}
+		err = db.Omit(omits...).Updates(po).Error
+	case map[string]any:
+		err = db.Updates(x).Error
+	default:
+		err = errx.New("only supported struct or map")
+	}
+
+	return
+}
+
+// Delete 删除记录, PO 包含软删除字段时为软删除. 不受 WithTrashed 影响, 永久删除使用 ForceDelete
+func (r *Repository[PO, Entity]) Delete(ctx context.Context, conditions ...any) (err error) {
+	db, err := Apply(r.GormDBHolder.GetWriteDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+
+	err = db.Delete(new(PO)).Error
+	return
+}
+
+// ForceDelete 永久删除记录, 包括已软删除的记录
+func (r *Repository[PO, Entity]) ForceDelete(ctx context.Context, conditions ...any) (err error) {
+	db, err := Apply(r.GormDBHolder.GetWriteDB(ctx).Unscoped(), conditions...)
 	if err != nil {
 		return
 	}
@@ -142,8 +328,29 @@ func (r *Repository[PO, Entity]) Delete(conditions ...any) (err error) {
 	return
 }
 
-func (r *Repository[PO, Entity]) First(conditions ...any) (entity *Entity, err error) {
-	db, err := Apply(r.GetDB(), conditions...)
+// Restore 恢复满足条件的软删除记录
+func (r *Repository[PO, Entity]) Restore(ctx context.Context, conditions ...any) (err error) {
+	db := r.GormDBHolder.GetWriteDB(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(new(PO)); err != nil {
+		return
+	}
+	field, alive, err := softDeleteField(stmt.Schema)
+	if err != nil {
+		return
+	}
+
+	db, err = Apply(applyTrashed(db, database.OnlyTrashed), conditions...)
+	if err != nil {
+		return
+	}
+
+	err = db.Model(new(PO)).Update(field.DBName, alive).Error
+	return
+}
+
+func (r *Repository[PO, Entity]) First(ctx context.Context, conditions ...any) (entity *Entity, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
 	if err != nil {
 		return
 	}
@@ -159,33 +366,98 @@ func (r *Repository[PO, Entity]) First(conditions ...any) (entity *Entity, err e
 	return
 }
 
-func (r *Repository[PO, Entity]) List(conditions ...any) (list []*Entity, err error) {
-	db, err := Apply(r.GetDB(), conditions...)
+// FirstOrCreate 查询满足 conditions 的第一条记录并映射到 entity, 不存在时创建 entity. 查询和创建不是原子的, 需要唯一索引保证不重复
+func (r *Repository[PO, Entity]) FirstOrCreate(ctx context.Context, entity *Entity, conditions ...any) (created bool, err error) {
+	db, err := Apply(r.GetWriteDB(ctx), conditions...)
 	if err != nil {
 		return
 	}
 
-	var poList []PO
-	err = db.Find(&poList).Error
+	po := new(PO)
+	err = db.First(po).Error
+	if err == nil {
+		err = r.M2E(entity, po)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	err = r.Create(ctx, entity)
+	created = err == nil
+	return
+}
+
+func (r *Repository[PO, Entity]) Count(ctx context.Context, conditions ...any) (count int64, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
 	if err != nil {
 		return
 	}
 
-	list = make([]*Entity, 0, len(poList))
-	for _, item := range poList {
-		var dst Entity
-		err = r.M2E(&dst, &item)
-		if err != nil {
-			return
-		}
-		list = append(list, &dst)
+	err = db.Model(new(PO)).Count(&count).Error
+	return
+}
+
+func (r *Repository[PO, Entity]) Exists(ctx context.Context, conditions ...any) (exists bool, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
 	}
 
+	var one []int
+	err = db.Model(new(PO)).Select("1").Limit(1).Find(&one).Error
+	exists = len(one) > 0
 	return
 }
 
-func (r *Repository[PO, Entity]) Pagination(offset, limit int, conditions ...any) (total int64, list []*Entity, err error) {
-	db, err := Apply(r.GetDB(), conditions...)
+// Pluck 查询单个列的值到 dest, dest 为切片指针
+func (r *Repository[PO, Entity]) Pluck(ctx context.Context, column string, dest any, conditions ...any) (err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+
+	err = db.Model(new(PO)).Pluck(column, dest).Error
+	return
+}
+
+// Sum 计算 column 的和到 dest, 没有记录时为 0
+func (r *Repository[PO, Entity]) Sum(ctx context.Context, column string, dest any, conditions ...any) (err error) {
+	return r.aggregate(ctx, "COALESCE(SUM(?), 0)", column, dest, conditions...)
+}
+
+// Max 计算 column 的最大值到 dest, 没有记录时为 NULL, 需要时使用指针或 sql.Null* 类型接收
+func (r *Repository[PO, Entity]) Max(ctx context.Context, column string, dest any, conditions ...any) (err error) {
+	return r.aggregate(ctx, "MAX(?)", column, dest, conditions...)
+}
+
+func (r *Repository[PO, Entity]) aggregate(ctx context.Context, expr string, column string, dest any, conditions ...any) (err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+
+	err = db.Model(new(PO)).Select(expr, clause.Column{Name: column}).Scan(dest).Error
+	return
+}
+
+func (r *Repository[PO, Entity]) List(ctx context.Context, conditions ...any) (list []*Entity, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+
+	var poList []PO
+	err = db.Find(&poList).Error
+	if err != nil {
+		return
+	}
+
+	return r.toEntities(poList)
+}
+
+func (r *Repository[PO, Entity]) Pagination(ctx context.Context, offset, limit int, conditions ...any) (total int64, list []*Entity, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
 	if err != nil {
 		return
 	}
@@ -200,10 +472,76 @@ func (r *Repository[PO, Entity]) Pagination(offset, limit int, conditions ...any
 		return
 	}
 
+	list, err = r.toEntities(poList)
+	return
+}
+
+// CursorPagination 游标分页, 不统计总数, 翻页的性能不受页码影响. conditions 中不应包含排序, 顺序由 page.Sorts 决定
+func (r *Repository[PO, Entity]) CursorPagination(ctx context.Context, page database.CursorPage, conditions ...any) (cursor database.Cursor, list []*Entity, err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+
+	cursor, poList, err := cursorQuery[PO](ctx, db, page)
+	if err != nil {
+		return
+	}
+
+	list, err = r.toEntities(poList)
+	return
+}
+
+// Chunk 按主键顺序每次查询 size 条记录并转换后交给 f, f 返回错误时停止
+func (r *Repository[PO, Entity]) Chunk(ctx context.Context, size int, f func(list []*Entity) error, conditions ...any) (err error) {
+	db, err := Apply(r.GetDB(ctx), conditions...)
+	if err != nil {
+		return
+	}
+	db = db.Session(&gorm.Session{})
+
+	page := database.CursorPage{Size: size}
+	for {
+		var (
+			cursor database.Cursor
+			poList []PO
+			list   []*Entity
+		)
+		cursor, poList, err = cursorQuery[PO](ctx, db, page)
+		if err != nil || len(poList) == 0 {
+			return
+		}
+		list, err = r.toEntities(poList)
+		if err != nil {
+			return
+		}
+		if err = f(list); err != nil {
+			return
+		}
+		if cursor.Next == "" {
+			return
+		}
+		page.Cursor = cursor.Next
+	}
+}
+
+// Each 与 Chunk 相同, 但逐条交给 f
+func (r *Repository[PO, Entity]) Each(ctx context.Context, size int, f func(entity *Entity) error, conditions ...any) (err error) {
+	return r.Chunk(ctx, size, func(list []*Entity) (err error) {
+		for _, entity := range list {
+			if err = f(entity); err != nil {
+				return
+			}
+		}
+		return
+	}, conditions...)
+}
+
+func (r *Repository[PO, Entity]) toEntities(poList []PO) (list []*Entity, err error) {
 	list = make([]*Entity, 0, len(poList))
-	for _, item := range poList {
+	for i := range poList {
 		var dst Entity
-		err = r.M2E(&dst, &item)
+		err = r.M2E(&dst, &poList[i])
 		if err != nil {
 			return
 		}
@@ -249,6 +587,18 @@ func Apply(db *gorm.DB, conditions ...any) (newDB *gorm.DB, err error) {
 			if err != nil {
 				return
 			}
+		case database.Expr:
+			newDB, err = applyExpr(newDB, x)
+			if err != nil {
+				return
+			}
+		case []database.Expr:
+			newDB, err = applyExpr(newDB, x...)
+			if err != nil {
+				return
+			}
+		case database.Trashed:
+			newDB = applyTrashed(newDB, x)
 		case database.Order:
 			for _, item := range x {
 				newDB = newDB.Order(item)
@@ -0,0 +1,120 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/database"
+)
+
+type repoItem struct {
+	ID    uint64
+	Code  string `gorm:"uniqueIndex"`
+	Score int
+}
+
+func TestRepositoryExtended(t *testing.T) {
+	holder := newTestHolder(t)
+	ctx := context.Background()
+	require.NoError(t, holder.GetDB(ctx).AutoMigrate(&repoItem{}))
+	repo := NewRepository[repoItem, repoItem](WithDBHolder[repoItem, repoItem](holder))
+
+	require.NoError(t, repo.Create(ctx, &repoItem{Code: "a", Score: 1}, &repoItem{Code: "b", Score: 2}, &repoItem{Code: "c", Score: 3}))
+
+	t.Run("count and exists", func(t *testing.T) {
+		count, err := repo.Count(ctx, database.Gt("score", 1))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		exists, err := repo.Exists(ctx, database.Eq("code", "a"))
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		exists, err = repo.Exists(ctx, database.Eq("code", "x"))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("aggregates", func(t *testing.T) {
+		var codes []string
+		require.NoError(t, repo.Pluck(ctx, "code", &codes, database.In("score", 1, 3)))
+		require.ElementsMatch(t, []string{"a", "c"}, codes)
+
+		var sum int
+		require.NoError(t, repo.Sum(ctx, "score", &sum))
+		require.Equal(t, 6, sum)
+		require.NoError(t, repo.Sum(ctx, "score", &sum, database.Eq("code", "x")))
+		require.Equal(t, 0, sum)
+
+		var max sql.NullInt64
+		require.NoError(t, repo.Max(ctx, "score", &max, database.Lt("score", 3)))
+		require.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, max)
+		require.NoError(t, repo.Max(ctx, "score", &max, database.Eq("code", "x")))
+		require.False(t, max.Valid)
+	})
+
+	t.Run("first or create", func(t *testing.T) {
+		item := &repoItem{Code: "a", Score: 9}
+		created, err := repo.FirstOrCreate(ctx, item, database.Eq("code", "a"))
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, 1, item.Score)
+
+		item = &repoItem{Code: "d", Score: 4}
+		created, err = repo.FirstOrCreate(ctx, item, database.Eq("code", "d"))
+		require.NoError(t, err)
+		require.True(t, created)
+		require.NotZero(t, item.ID)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		require.NoError(t, repo.Upsert(ctx, database.OnConflict{Columns: []string{"code"}, Updates: []string{"score"}},
+			&repoItem{Code: "a", Score: 10}, &repoItem{Code: "e", Score: 5}))
+		item, err := repo.First(ctx, database.Eq("code", "a"))
+		require.NoError(t, err)
+		require.Equal(t, 10, item.Score)
+
+		require.NoError(t, repo.Upsert(ctx, database.OnConflict{Columns: []string{"code"}, DoNothing: true}, &repoItem{Code: "e", Score: 50}))
+		item, err = repo.First(ctx, database.Eq("code", "e"))
+		require.NoError(t, err)
+		require.Equal(t, 5, item.Score)
+	})
+
+	t.Run("update by ids", func(t *testing.T) {
+		var ids []uint64
+		require.NoError(t, repo.Pluck(ctx, "id", &ids, database.In("code", "b", "c")))
+		require.NoError(t, repo.UpdateByIDs(ctx, ids, map[string]any{"score": 100}))
+		require.NoError(t, repo.UpdateByIDs(ctx, ids[:1], &repoItem{ID: ids[1], Score: 200}))
+		require.NoError(t, repo.UpdateByIDs(ctx, []uint64{}, map[string]any{"score": 0}))
+		require.Error(t, repo.UpdateByIDs(ctx, ids[0], map[string]any{"score": 0}))
+
+		var scores []int
+		require.NoError(t, repo.Pluck(ctx, "score", &scores, database.In("id", ids), database.Order{"id"}))
+		require.Equal(t, []int{200, 100}, scores)
+
+		count, err := repo.Count(ctx, database.Eq("score", 100))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		item, err := repo.First(ctx, database.Eq("code", "b"))
+		require.NoError(t, err)
+		require.Equal(t, ids[0], item.ID)
+	})
+}
+
+func TestWithConnection(t *testing.T) {
+	holder := newTestHolder(t)
+	c := container.NewContainer()
+	require.NoError(t, container.BindIn[*GormDBHolder](c, holder, container.WithKey("analytics")))
+
+	opt, err := WithConnection[repoItem, repoItem](c, "analytics")
+	require.NoError(t, err)
+	repo := NewRepository[repoItem, repoItem](opt)
+	require.Same(t, holder, repo.GormDBHolder)
+
+	_, err = WithConnection[repoItem, repoItem](c, "report")
+	require.ErrorContains(t, err, "resolve database connection <report> failed")
+}
@@ -0,0 +1,77 @@
+package gormx
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/zeddy-go/zeddy/database"
+	"github.com/zeddy-go/zeddy/errx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/soft_delete"
+)
+
+// SoftDelete 软删除字段, 删除时记录秒级时间戳, 未删除时为 0
+type SoftDelete struct {
+	DeletedAt soft_delete.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// SoftDeleteMilli 与 SoftDelete 相同, 但记录毫秒级时间戳
+type SoftDeleteMilli struct {
+	DeletedAt soft_delete.DeletedAt `json:"deleted_at" gorm:"softDelete:milli;index"`
+}
+
+var (
+	softDeleteType    = reflect.TypeOf(soft_delete.DeletedAt(0))
+	gormDeletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// softDeleteField 返回 s 的软删除字段和未删除时的值, 支持 soft_delete.DeletedAt 和 gorm.DeletedAt
+func softDeleteField(s *schema.Schema) (field *schema.Field, alive any, err error) {
+	if s != nil {
+		for _, f := range s.Fields {
+			switch f.FieldType {
+			case softDeleteType:
+				return f, 0, nil
+			case gormDeletedAtType:
+				return f, nil, nil
+			}
+		}
+	}
+
+	name := "<nil>"
+	if s != nil {
+		name = s.Name
+	}
+	err = errx.New(fmt.Sprintf("<%s> has no soft delete field", name))
+	return
+}
+
+// onlyTrashed 只查询已删除的记录, 在构建 SQL 时才能确定模型的软删除字段
+type onlyTrashed struct{}
+
+func (onlyTrashed) Build(builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+	field, alive, err := softDeleteField(stmt.Schema)
+	if err != nil {
+		_ = stmt.AddError(err)
+		return
+	}
+	clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: alive}.Build(builder)
+}
+
+// applyTrashed 按 trashed 设置是否查询已删除的记录
+func applyTrashed(db *gorm.DB, trashed database.Trashed) *gorm.DB {
+	switch trashed {
+	case database.WithTrashed:
+		return db.Unscoped()
+	case database.OnlyTrashed:
+		return db.Unscoped().Clauses(clause.Where{Exprs: []clause.Expression{onlyTrashed{}}})
+	default:
+		return db
+	}
+}
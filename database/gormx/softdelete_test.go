@@ -0,0 +1,84 @@
+package gormx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
+	"gorm.io/gorm"
+)
+
+type softItem struct {
+	ID   uint64
+	Name string
+	SoftDeleteMilli
+}
+
+type gormSoftItem struct {
+	ID        uint64
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func testSoftDelete[PO any](t *testing.T, holder *GormDBHolder, newPO func(name string) *PO) {
+	ctx := context.Background()
+	require.NoError(t, holder.GetDB(ctx).AutoMigrate(new(PO)))
+	same := func(dst *PO, src *PO) error {
+		*dst = *src
+		return nil
+	}
+	repo := NewRepository[PO, PO](WithDBHolder[PO, PO](holder), WithM2E[PO, PO](same), WithE2M[PO, PO](same))
+	require.NoError(t, repo.Create(ctx, newPO("a"), newPO("b"), newPO("c")))
+
+	count := func(conditions ...any) int64 {
+		n, err := repo.Count(ctx, conditions...)
+		require.NoError(t, err)
+		return n
+	}
+
+	require.NoError(t, repo.Delete(ctx, database.In("name", "a", "b")))
+	require.Equal(t, int64(1), count())
+	require.Equal(t, int64(3), count(database.WithTrashed))
+	require.Equal(t, int64(2), count(database.OnlyTrashed))
+
+	list, err := repo.OnlyTrashed().List(ctx, database.Order{"name"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	n, err := repo.WithTrashed().Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	// WithTrashed 不影响 Delete, 只软删除
+	require.NoError(t, repo.WithTrashed().Delete(ctx, database.Eq("name", "c")))
+	require.Equal(t, int64(3), count(database.OnlyTrashed))
+
+	require.NoError(t, repo.Restore(ctx, database.Eq("name", "a")))
+	require.Equal(t, int64(1), count())
+	_, err = repo.First(ctx, database.Eq("name", "a"))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.ForceDelete(ctx, database.Eq("name", "b")))
+	require.Equal(t, int64(2), count(database.WithTrashed))
+
+	require.NoError(t, repo.Restore(ctx))
+	require.Equal(t, int64(2), count())
+}
+
+func TestSoftDelete(t *testing.T) {
+	t.Run("soft_delete", func(t *testing.T) {
+		testSoftDelete(t, newTestHolder(t), func(name string) *softItem { return &softItem{Name: name} })
+	})
+
+	t.Run("gorm", func(t *testing.T) {
+		testSoftDelete(t, newTestHolder(t), func(name string) *gormSoftItem { return &gormSoftItem{Name: name} })
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		holder := newTestHolder(t)
+		repo := NewRepository[txUser, txUser](WithDBHolder[txUser, txUser](holder))
+		require.Error(t, repo.Restore(context.Background()))
+		_, err := repo.OnlyTrashed().List(context.Background())
+		require.Error(t, err)
+	})
+}
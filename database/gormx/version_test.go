@@ -0,0 +1,72 @@
+package gormx
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
+	"github.com/zeddy-go/zeddy/errx"
+	"gorm.io/gorm"
+)
+
+type versionItem struct {
+	ID   uint64
+	Name string
+	Version
+}
+
+func TestOptimisticLock(t *testing.T) {
+	holder := newTestHolder(t)
+	ctx := context.Background()
+	require.NoError(t, holder.GetDB(ctx).AutoMigrate(&versionItem{}))
+	repo := NewRepository[versionItem, versionItem](WithDBHolder[versionItem, versionItem](holder))
+
+	item := &versionItem{Name: "a"}
+	require.NoError(t, repo.Create(ctx, item))
+	require.Equal(t, int64(0), item.Version.Version)
+
+	stale, err := repo.First(ctx, database.Eq("id", item.ID))
+	require.NoError(t, err)
+
+	item.Name = "b"
+	require.NoError(t, repo.Update(ctx, item))
+	require.Equal(t, int64(1), item.Version.Version)
+
+	stale.Name = "c"
+	err = repo.Update(ctx, stale)
+	require.ErrorIs(t, err, database.ErrVersionConflict)
+	require.Equal(t, http.StatusConflict, errx.GetErrxField[int](err, errx.Code))
+	require.Equal(t, int64(0), stale.Version.Version)
+
+	latest, err := repo.First(ctx, database.Eq("id", item.ID))
+	require.NoError(t, err)
+	require.Equal(t, "b", latest.Name)
+	require.Equal(t, int64(1), latest.Version.Version)
+
+	latest.Name = "d"
+	require.NoError(t, repo.Update(ctx, latest))
+	require.Equal(t, int64(2), latest.Version.Version)
+
+	// 记录不存在时同样视为冲突
+	missing := &versionItem{ID: 999, Name: "x", Version: Version{Version: 3}}
+	require.ErrorIs(t, repo.Update(ctx, missing), database.ErrVersionConflict)
+	require.Equal(t, int64(3), missing.Version.Version)
+
+	// 主键为零值时拒绝更新, 不能只按版本号更新全部记录
+	require.NoError(t, repo.Create(ctx, &versionItem{Name: "e"}, &versionItem{Name: "f"}))
+	zero := &versionItem{Name: "zzz"}
+	require.ErrorIs(t, repo.Update(ctx, zero), gorm.ErrMissingWhereClause)
+	require.Equal(t, int64(0), zero.Version.Version)
+	count, err := repo.Count(ctx, database.Eq("name", "zzz"))
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// UpdateByIDs 不写入版本号
+	require.NoError(t, repo.UpdateByIDs(ctx, []uint64{item.ID}, &versionItem{Name: "g", Version: Version{Version: 9}}))
+	latest, err = repo.First(ctx, database.Eq("id", item.ID))
+	require.NoError(t, err)
+	require.Equal(t, "g", latest.Name)
+	require.Equal(t, int64(2), latest.Version.Version)
+}
@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// DatabaseDriver 使用 db 创建 golang-migrate 的数据库驱动, 驱动关闭时不能关闭 db
+type DatabaseDriver func(ctx context.Context, db *sql.DB) (migratedb.Driver, error)
+
+var (
+	databaseDrivers     = make(map[string]DatabaseDriver)
+	databaseDriversLock sync.RWMutex
+)
+
+func init() {
+	RegisterDatabaseDriver("mysql", func(ctx context.Context, db *sql.DB) (driver migratedb.Driver, err error) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return
+		}
+		return mysql.WithConnection(ctx, conn, &mysql.Config{})
+	})
+	RegisterDatabaseDriver("postgres", func(ctx context.Context, db *sql.DB) (driver migratedb.Driver, err error) {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return
+		}
+		return postgres.WithConnection(ctx, conn, &postgres.Config{})
+	})
+	RegisterDatabaseDriver("sqlite", func(ctx context.Context, db *sql.DB) (driver migratedb.Driver, err error) {
+		driver, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+		if err != nil {
+			return
+		}
+		return keepOpen{driver}, nil
+	})
+}
+
+// RegisterDatabaseDriver 注册数据库方言对应的迁移驱动, 方言与 gorm Dialector 的 Name 一致.
+// 内置 mysql, postgres 和 sqlite. 没有内置 sqlserver: golang-migrate 的 sqlserver 驱动关闭时会关闭传入的 *sql.DB
+// 且独占一个连接, 不能与应用共享连接池
+func RegisterDatabaseDriver(dialect string, driver DatabaseDriver) {
+	databaseDriversLock.Lock()
+	defer databaseDriversLock.Unlock()
+
+	databaseDrivers[dialect] = driver
+}
+
+func databaseDriver(dialect string) (driver DatabaseDriver, err error) {
+	databaseDriversLock.RLock()
+	defer databaseDriversLock.RUnlock()
+
+	driver, ok := databaseDrivers[dialect]
+	if !ok {
+		err = errx.New(fmt.Sprintf("migrate driver <%s> not registered", dialect))
+	}
+	return
+}
+
+// keepOpen 关闭时不关闭共享的 *sql.DB
+type keepOpen struct {
+	migratedb.Driver
+}
+
+func (keepOpen) Close() error {
+	return nil
+}
@@ -1,5 +1,3 @@
-//go:build !sqlite
-
 package migrate
 
 import (
@@ -7,8 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	"io/fs"
 )
 
@@ -16,22 +12,47 @@ func NewDefaultMigrator(db *sql.DB) *DefaultMigrator {
 	return &DefaultMigrator{
 		db:             db,
 		SourceInstance: NewFsDriver(),
+		Dialect:        "mysql",
 	}
 }
 
 type DefaultMigrator struct {
 	SourceInstance *EmbedDriver
-	db             *sql.DB
+	// Dialect 数据库方言, 用于选择迁移驱动, 见 RegisterDatabaseDriver
+	Dialect string
+	db      *sql.DB
 }
 
-func (d *DefaultMigrator) Up(stepNum int) error {
-	//TODO implement me
-	panic("implement me")
+// Up 执行 stepNum 个迁移, stepNum <= 0 时执行全部未执行的迁移
+func (d *DefaultMigrator) Up(stepNum int) (err error) {
+	return d.run(func(m *migrate.Migrate) error {
+		if stepNum <= 0 {
+			return m.Up()
+		}
+		return m.Steps(stepNum)
+	})
 }
 
-func (d *DefaultMigrator) Down(stepNum int) error {
-	//TODO implement me
-	panic("implement me")
+// Down 回滚 stepNum 个迁移, stepNum <= 0 时回滚全部迁移
+func (d *DefaultMigrator) Down(stepNum int) (err error) {
+	return d.run(func(m *migrate.Migrate) error {
+		if stepNum <= 0 {
+			return m.Down()
+		}
+		return m.Steps(-stepNum)
+	})
+}
+
+// Version 返回当前迁移版本, 以及上次迁移是否失败
+func (d *DefaultMigrator) Version() (version uint, dirty bool, err error) {
+	err = d.run(func(m *migrate.Migrate) (err error) {
+		version, dirty, err = m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			err = nil
+		}
+		return
+	})
+	return
 }
 
 func (d *DefaultMigrator) RegisterMigrates(ms ...any) (err error) {
@@ -43,14 +64,17 @@ func (d *DefaultMigrator) RegisterMigrates(ms ...any) (err error) {
 }
 
 func (d *DefaultMigrator) Migrate() (err error) {
+	return d.Up(0)
+}
+
+func (d *DefaultMigrator) run(f func(m *migrate.Migrate) error) (err error) {
 	var m *migrate.Migrate
 
-	ctx := context.Background()
-	newConn, err := d.db.Conn(ctx)
+	driver, err := databaseDriver(d.Dialect)
 	if err != nil {
 		return
 	}
-	dbInstance, err := mysql.WithConnection(ctx, newConn, &mysql.Config{})
+	dbInstance, err := driver(context.Background(), d.db)
 	if err != nil {
 		return
 	}
@@ -62,7 +86,7 @@ func (d *DefaultMigrator) Migrate() (err error) {
 		_, _ = m.Close()
 	}()
 
-	err = m.Up()
+	err = f(m)
 	if errors.Is(err, migrate.ErrNoChange) {
 		err = nil
 	}
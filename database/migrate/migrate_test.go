@@ -0,0 +1,44 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSqliteMigrate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	m := NewDefaultMigrator(sqlDB)
+	m.Dialect = db.Dialector.Name()
+	require.NoError(t, m.RegisterMigrates(fstest.MapFS{
+		"1_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"1_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}))
+
+	require.NoError(t, m.Migrate())
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.Equal(t, uint(1), version)
+	require.False(t, dirty)
+
+	// 迁移结束后共享的连接仍然可用
+	require.NoError(t, db.Exec("INSERT INTO users (name) VALUES (?)", "a").Error)
+
+	require.NoError(t, m.Down(0))
+	require.Error(t, db.Exec("INSERT INTO users (name) VALUES (?)", "a").Error)
+}
+
+func TestUnknownDialect(t *testing.T) {
+	m := NewDefaultMigrator(nil)
+	m.Dialect = "oracle"
+	require.ErrorContains(t, m.Migrate(), "migrate driver <oracle> not registered")
+}
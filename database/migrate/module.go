@@ -4,6 +4,7 @@ import (
 	"github.com/zeddy-go/zeddy/app"
 	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/database"
+	"gorm.io/gorm"
 )
 
 func NewModule() *Module {
@@ -16,25 +17,27 @@ type Module struct {
 	app.IsModule
 }
 
-func (m Module) Init() (err error) {
-	err = container.Bind[database.Migrator](NewDefaultMigrator)
-	if err != nil {
-		return
-	}
+func (m *Module) Name() string {
+	return "migrate"
+}
 
-	return
+func (m *Module) Dependencies() []string {
+	return []string{"gormx"}
 }
 
-func (m Module) Boot() (err error) {
-	err = container.Invoke(func(m database.Migrator) (err error) {
-		err = m.Migrate()
+func (m *Module) Init() (err error) {
+	err = container.BindIn[database.Migrator](m.Container(), func(db *gorm.DB) (migrator *DefaultMigrator, err error) {
+		sqlDB, err := db.DB()
 		if err != nil {
 			return
 		}
+		migrator = NewDefaultMigrator(sqlDB)
+		migrator.Dialect = db.Dialector.Name()
 		return
 	})
 	if err != nil {
 		return
 	}
+
 	return
 }
@@ -0,0 +1,269 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/zeddy-go/zeddy/convert"
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// Expr 类型化的查询条件, 由具体的数据库实现编译为对应方言的 SQL
+type Expr interface {
+	isExpr()
+}
+
+// CompareExpr 比较条件, 如 `field` > ?. Op 为 Op* 以外的运算符时编译为 `field` op (?), 见 ValidOperator
+type CompareExpr struct {
+	Field string
+	Op    string
+	Value any
+}
+
+// InExpr `field` IN (...)
+type InExpr struct {
+	Field  string
+	Values []any
+}
+
+// BetweenExpr `field` BETWEEN ? AND ?
+type BetweenExpr struct {
+	Field string
+	From  any
+	To    any
+}
+
+// LikeExpr `field` LIKE ?, Pattern 原样传递, 不做转义
+type LikeExpr struct {
+	Field   string
+	Pattern string
+}
+
+// NullExpr `field` IS NULL
+type NullExpr struct {
+	Field string
+}
+
+// NotExpr 对条件取反
+type NotExpr struct {
+	Expr Expr
+}
+
+// AndExpr 条件全部满足
+type AndExpr struct {
+	Exprs []Expr
+}
+
+// OrExpr 满足任一条件
+type OrExpr struct {
+	Exprs []Expr
+}
+
+// RawExpr 原生 SQL 条件, 仅用于无法用其它条件表达的场景
+type RawExpr struct {
+	SQL  string
+	Args []any
+}
+
+func (CompareExpr) isExpr() {}
+func (InExpr) isExpr()      {}
+func (BetweenExpr) isExpr() {}
+func (LikeExpr) isExpr()    {}
+func (NullExpr) isExpr()    {}
+func (NotExpr) isExpr()     {}
+func (AndExpr) isExpr()     {}
+func (OrExpr) isExpr()      {}
+func (RawExpr) isExpr()     {}
+
+// 支持的比较运算符
+const (
+	OpEq  = "="
+	OpNe  = "<>"
+	OpGt  = ">"
+	OpGte = ">="
+	OpLt  = "<"
+	OpLte = "<="
+)
+
+// operator 允许原样写入 SQL 的运算符: 单词(如 regexp, not ilike)或符号(如 <=>, ~*)
+var operator = regexp.MustCompile(`^([a-z]+( [a-z]+)*|[<>=!~*@&|^#%-]+)$`)
+
+// ValidOperator 判断 op 是否可以作为运算符原样写入 SQL, 防止通过运算符注入
+func ValidOperator(op string) bool {
+	return operator.MatchString(op)
+}
+
+func Eq(field string, value any) Expr  { return CompareExpr{Field: field, Op: OpEq, Value: value} }
+func Ne(field string, value any) Expr  { return CompareExpr{Field: field, Op: OpNe, Value: value} }
+func Gt(field string, value any) Expr  { return CompareExpr{Field: field, Op: OpGt, Value: value} }
+func Gte(field string, value any) Expr { return CompareExpr{Field: field, Op: OpGte, Value: value} }
+func Lt(field string, value any) Expr  { return CompareExpr{Field: field, Op: OpLt, Value: value} }
+func Lte(field string, value any) Expr { return CompareExpr{Field: field, Op: OpLte, Value: value} }
+
+// In values 可以是多个值, 也可以是单个切片
+func In(field string, values ...any) Expr {
+	if len(values) == 1 {
+		if list, ok := toSlice(values[0]); ok {
+			values = list
+		}
+	}
+	return InExpr{Field: field, Values: values}
+}
+
+func Between(field string, from, to any) Expr {
+	return BetweenExpr{Field: field, From: from, To: to}
+}
+
+func Like(field string, pattern string) Expr {
+	return LikeExpr{Field: field, Pattern: pattern}
+}
+
+// Contains `field` LIKE %s%
+func Contains(field string, s string) Expr {
+	return LikeExpr{Field: field, Pattern: "%" + s + "%"}
+}
+
+func IsNull(field string) Expr {
+	return NullExpr{Field: field}
+}
+
+func NotNull(field string) Expr {
+	return Not(IsNull(field))
+}
+
+func Not(expr Expr) Expr {
+	return NotExpr{Expr: expr}
+}
+
+func And(exprs ...Expr) Expr {
+	return AndExpr{Exprs: exprs}
+}
+
+func Or(exprs ...Expr) Expr {
+	return OrExpr{Exprs: exprs}
+}
+
+func Raw(sql string, args ...any) Expr {
+	return RawExpr{SQL: sql, Args: args}
+}
+
+func toSlice(value any) (list []any, ok bool) {
+	if _, isBytes := value.([]byte); isBytes {
+		return
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return
+	}
+	list = make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		list = append(list, v.Index(i).Interface())
+	}
+	return list, true
+}
+
+// 旧写法中出现这些片段时视为原生 SQL
+var rawMarkers = []string{" and ", " or ", "?", " not ", " between ", " like ", " is "}
+
+// Expr 将旧的 Condition 转换为 Expr:
+//   - {"id = ? or no = ?", 1, 2} 原生 SQL
+//   - {"id", 1} 等于, 值为切片时为 IN
+//   - {"id", ">", 1} 比较, 运算符还支持 like, not like, in, not in, between, is, is not.
+//     like 会在值两侧加 %, not like 的值原样传递.
+//     其它运算符(如 regexp, ilike)与旧版本一样编译为 `field` op (?), 但运算符必须满足 ValidOperator
+func (c Condition) Expr() (expr Expr, err error) {
+	if len(c) < 2 {
+		err = errx.New("condition require at least 2 params")
+		return
+	}
+
+	field, ok := c[0].(string)
+	if !ok {
+		err = errx.New(fmt.Sprintf("condition field must be string, got %T", c[0]))
+		return
+	}
+
+	lower := strings.ToLower(field)
+	for _, marker := range rawMarkers {
+		if strings.Contains(lower, marker) {
+			return Raw(field, c[1:]...), nil
+		}
+	}
+
+	switch len(c) {
+	case 2:
+		if list, ok := toSlice(c[1]); ok {
+			return In(field, list...), nil
+		}
+		return Eq(field, c[1]), nil
+	case 3:
+		op, ok := c[1].(string)
+		if !ok {
+			err = errx.New(fmt.Sprintf("condition operator must be string, got %T", c[1]))
+			return
+		}
+		return compare(field, op, c[2])
+	default:
+		err = errx.New("condition params is too many")
+		return
+	}
+}
+
+func compare(field string, op string, value any) (expr Expr, err error) {
+	op = strings.Join(strings.Fields(strings.ToLower(op)), " ")
+	switch op {
+	case OpEq:
+		expr = Eq(field, value)
+	case OpNe, "!=":
+		expr = Ne(field, value)
+	case OpGt:
+		expr = Gt(field, value)
+	case OpGte:
+		expr = Gte(field, value)
+	case OpLt:
+		expr = Lt(field, value)
+	case OpLte:
+		expr = Lte(field, value)
+	case "like", "not like":
+		var s string
+		s, err = convert.To[string](value)
+		if err != nil {
+			return
+		}
+		// 与旧版本一致, like 在值两侧加 %, not like 的值原样传递
+		if op == "like" {
+			expr = Contains(field, s)
+		} else {
+			expr = Not(Like(field, s))
+		}
+	case "in":
+		expr = In(field, value)
+	case "not in":
+		expr = Not(In(field, value))
+	case "between":
+		list, ok := toSlice(value)
+		if !ok || len(list) != 2 {
+			err = errx.New("between condition require 2 values")
+			return
+		}
+		expr = Between(field, list[0], list[1])
+	case "is", "is not":
+		if value != nil {
+			err = errx.New(fmt.Sprintf("%s condition only support nil value", op))
+			return
+		}
+		expr = IsNull(field)
+		if op == "is not" {
+			expr = Not(expr)
+		}
+	default:
+		if !ValidOperator(op) {
+			err = errx.New(fmt.Sprintf("unsupported condition operator: %s", op))
+			return
+		}
+		expr = CompareExpr{Field: field, Op: op, Value: value}
+	}
+	return
+}
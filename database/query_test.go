@@ -0,0 +1,42 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionExpr(t *testing.T) {
+	cases := []struct {
+		cond Condition
+		expr Expr
+	}{
+		{Condition{"id", 1}, Eq("id", 1)},
+		{Condition{"id", []int{1, 2}}, In("id", 1, 2)},
+		{Condition{"id", "!=", 1}, Ne("id", 1)},
+		{Condition{"name", "LIKE", 1}, Contains("name", "1")},
+		{Condition{"name", "not like", "a%"}, Not(Like("name", "a%"))},
+		{Condition{"id", "not in", []int{1}}, Not(In("id", 1))},
+		{Condition{"age", "between", []int{1, 2}}, Between("age", 1, 2)},
+		{Condition{"id = ? or no = ?", 1, 2}, Raw("id = ? or no = ?", 1, 2)},
+		{Condition{"name", "NOT  REGEXP", "^a"}, CompareExpr{Field: "name", Op: "not regexp", Value: "^a"}},
+		{Condition{"email", "is", nil}, IsNull("email")},
+	}
+	for _, c := range cases {
+		expr, err := c.cond.Expr()
+		require.NoError(t, err)
+		require.Equal(t, c.expr, expr)
+	}
+
+	for _, cond := range []Condition{
+		{"id"},
+		{1, 1},
+		{"id", "= 1 or 1", 1},
+		{"id", "is", 1},
+		{"age", "between", 1},
+		{"id", "=", 1, 2},
+	} {
+		_, err := cond.Expr()
+		require.Error(t, err)
+	}
+}
@@ -1,12 +1,17 @@
 package redis
 
 import (
+	"context"
 	"github.com/go-redis/redis/v8"
-	"github.com/spf13/viper"
 	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/configx"
 	"github.com/zeddy-go/zeddy/container"
 )
 
+// defaultPrefix 默认的配置前缀
+const defaultPrefix = "redis"
+
+// WithPrefix 指定配置前缀, 与默认前缀不同时模块名称为 "redis.<prefix>", 以便同一应用中使用多个实例
 func WithPrefix(prefix string) func(*Module) {
 	return func(module *Module) {
 		module.prefix = prefix
@@ -15,7 +20,7 @@ func WithPrefix(prefix string) func(*Module) {
 
 func NewModule(opts ...func(*Module)) *Module {
 	m := &Module{
-		prefix: "redis",
+		prefix: defaultPrefix,
 	}
 
 	for _, opt := range opts {
@@ -26,22 +31,54 @@ func NewModule(opts ...func(*Module)) *Module {
 }
 
 type Module struct {
-	app.Module
+	app.IsModule
 	prefix string
 }
 
-func (m Module) Init() (err error) {
-	err = container.Bind[*redis.Client](func(c *viper.Viper) *redis.Client {
-		c = c.Sub(m.prefix)
+// Name 使用默认前缀时为 "redis", 否则为 "redis.<prefix>"
+func (m *Module) Name() string {
+	if m.prefix == defaultPrefix {
+		return "redis"
+	}
+
+	return "redis." + m.prefix
+}
+
+func (m *Module) Dependencies() []string {
+	return []string{"configx"}
+}
+
+// Config redis 连接配置
+type Config struct {
+	Addr     string `mapstructure:"addr" validate:"required"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db" validate:"gte=0"`
+}
+
+func (m *Module) Init() (err error) {
+	err = configx.Bind[Config](m.Container(), m.prefix)
+	if err != nil {
+		return
+	}
+
+	err = container.BindIn[*redis.Client](m.Container(), func(c Config) *redis.Client {
 		return redis.NewClient(&redis.Options{
-			Addr:     c.GetString("addr"),
-			Password: c.GetString("password"),
-			DB:       c.GetInt("db"),
+			Addr:     c.Addr,
+			Password: c.Password,
+			DB:       c.DB,
 		})
 	})
 	if err != nil {
 		return
 	}
 
+	m.App().Health().Register(m.Name(), func(ctx context.Context) (err error) {
+		client, err := container.ResolveIn[*redis.Client](m.Container())
+		if err != nil {
+			return
+		}
+		return client.Ping(ctx).Err()
+	})
+
 	return
 }
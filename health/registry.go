@@ -0,0 +1,198 @@
+// Package health 健康检查注册表, 模块向其注册检查项, 由 http/grpc 等服务对外暴露.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeddy-go/zeddy/errx"
+)
+
+// Kind 检查项的类型, 可以组合使用
+type Kind int
+
+const (
+	// Liveness 存活检查, 失败表示进程需要被重启
+	Liveness Kind = 1 << iota
+	// Readiness 就绪检查, 失败表示暂时不能接收流量
+	Readiness
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// DefaultTimeout 单个检查项的默认超时时间
+const DefaultTimeout = 3 * time.Second
+
+// Check 检查函数, 返回 nil 表示健康
+type Check func(ctx context.Context) error
+
+type checkOpts struct {
+	kind    Kind
+	timeout time.Duration
+}
+
+// WithKind 指定检查项的类型, 默认为 Readiness
+func WithKind(kind Kind) func(*checkOpts) {
+	return func(opts *checkOpts) {
+		opts.kind = kind
+	}
+}
+
+// WithTimeout 指定检查项的超时时间
+func WithTimeout(timeout time.Duration) func(*checkOpts) {
+	return func(opts *checkOpts) {
+		opts.timeout = timeout
+	}
+}
+
+type check struct {
+	name string
+	f    Check
+	checkOpts
+}
+
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status Status        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+func (r Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		checks: make(map[string]*check),
+	}
+}
+
+// Registry 健康检查注册表, 可并发使用
+type Registry struct {
+	lock   sync.RWMutex
+	checks map[string]*check
+	ready  atomic.Bool
+}
+
+// Register 注册检查项, 同名检查项会被覆盖
+func (r *Registry) Register(name string, f Check, opts ...func(*checkOpts)) {
+	c := &check{
+		name: name,
+		f:    f,
+		checkOpts: checkOpts{
+			kind:    Readiness,
+			timeout: DefaultTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(&c.checkOpts)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checks[name] = c
+}
+
+// SetReady 设置应用是否已就绪, 应用未就绪时 Readiness 总是 down
+func (r *Registry) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, Liveness)
+}
+
+func (r *Registry) Readiness(ctx context.Context) (report Report) {
+	report = r.run(ctx, Readiness)
+	if !r.ready.Load() {
+		report.Status = StatusDown
+		report.Checks = append(report.Checks, CheckResult{
+			Name:   "app",
+			Status: StatusDown,
+			Error:  "app is not ready",
+		})
+	}
+
+	return
+}
+
+func (r *Registry) run(ctx context.Context, kind Kind) (report Report) {
+	r.lock.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if c.kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	r.lock.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+
+	report.Status = StatusUp
+	report.Checks = make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, item := range report.Checks {
+		if item.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+
+	return
+}
+
+func (c *check) run(ctx context.Context) (result CheckResult) {
+	result.Name = c.name
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				done <- errx.New("check panic")
+			}
+		}()
+		done <- c.f(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	} else {
+		result.Status = StatusUp
+	}
+
+	return
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", func(ctx context.Context) error {
+		return nil
+	})
+	r.Register("loop", func(ctx context.Context) error {
+		return nil
+	}, WithKind(Liveness|Readiness))
+
+	t.Run("not ready", func(t *testing.T) {
+		report := r.Readiness(context.Background())
+		require.False(t, report.IsUp())
+
+		report = r.Liveness(context.Background())
+		require.True(t, report.IsUp())
+		require.Len(t, report.Checks, 1)
+		require.Equal(t, "loop", report.Checks[0].Name)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		r.SetReady(true)
+		report := r.Readiness(context.Background())
+		require.True(t, report.IsUp())
+		require.Len(t, report.Checks, 2)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r.Register("redis", func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})
+		report := r.Readiness(context.Background())
+		require.False(t, report.IsUp())
+		require.Equal(t, CheckResult{Name: "redis", Status: StatusDown, Error: "connection refused"}, report.Checks[2])
+
+		require.True(t, r.Liveness(context.Background()).IsUp())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		r := NewRegistry()
+		r.SetReady(true)
+		r.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, WithTimeout(10*time.Millisecond))
+		report := r.Readiness(context.Background())
+		require.False(t, report.IsUp())
+		require.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+}
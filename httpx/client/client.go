@@ -151,6 +151,19 @@ func (c *Client) Do(request *Request) (resp *Response, err error) {
 	return
 }
 
+// debugLog 开启调试时通过 slog 输出请求和响应
+func (c *Client) debugLog(req *Request, resp *Response) {
+	if !c.debug {
+		return
+	}
+
+	var response any
+	if resp != nil {
+		response = resp.Info()
+	}
+	slog.DebugContext(req.Context(), "request debug", "request", req.Info(), "response", response)
+}
+
 func (c *Client) buildUrl(uri string) (u string, err error) {
 	var tmp *url.URL
 	if c.baseUrl != "" {
@@ -187,13 +200,7 @@ func (c *Client) Post(uri string, body any) (resp *Response, err error) {
 
 	resp, err = c.Do(req)
 
-	if c.debug {
-		if resp == nil {
-			slog.Debug("request debug", "request", req.Info(), "response", nil)
-		} else {
-			slog.Debug("request debug", "request", req.Info(), "response", resp.Info())
-		}
-	}
+	c.debugLog(req, resp)
 
 	return
 }
@@ -215,13 +222,7 @@ func (c *Client) Put(uri string, body any) (resp *Response, err error) {
 
 	resp, err = c.Do(req)
 
-	if c.debug {
-		if resp == nil {
-			slog.Debug("request debug", "request", req.Info(), "response", nil)
-		} else {
-			slog.Debug("request debug", "request", req.Info(), "response", resp.Info())
-		}
-	}
+	c.debugLog(req, resp)
 
 	return
 }
@@ -239,13 +240,7 @@ func (c *Client) Delete(uri string) (resp *Response, err error) {
 
 	resp, err = c.Do(req)
 
-	if c.debug {
-		if resp == nil {
-			slog.Debug("request debug", "request", req.Info(), "response", nil)
-		} else {
-			slog.Debug("request debug", "request", req.Info(), "response", resp.Info())
-		}
-	}
+	c.debugLog(req, resp)
 
 	return
 }
@@ -263,13 +258,7 @@ func (c *Client) Get(uri string) (resp *Response, err error) {
 
 	resp, err = c.Do(req)
 
-	if c.debug {
-		if resp == nil {
-			slog.Debug("request debug", "request", req.Info(), "response", "null")
-		} else {
-			slog.Debug("request debug", "request", req.Info(), "response", resp.Info())
-		}
-	}
+	c.debugLog(req, resp)
 
 	return
 }
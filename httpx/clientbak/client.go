@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/zeddy-go/zeddy/mapx"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -307,14 +308,15 @@ func (c *Client) Request(req *http.Request) (resp *Response, err error) {
 		}
 		res.Body = io.NopCloser(bytes.NewReader(respContent))
 
-		fmt.Printf(
-			"[request: method=%s, url=%s, body=%s, header=%v]\n[request time: %fs]\n[response: body=%s]\n",
-			req.Method,
-			req.URL.String(),
-			string(content),
-			req.Header,
-			time.Since(start).Seconds(),
-			string(respContent),
+		slog.DebugContext(
+			req.Context(),
+			"request debug",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"body", string(content),
+			"header", req.Header,
+			"elapsed", time.Since(start),
+			"response", string(respContent),
 		)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/stoewer/go-strcase"
+	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/stringx"
 	"gorm.io/gorm"
 	"strings"
@@ -21,6 +22,25 @@ func NewPageFromCtx(ctx *gin.Context, defaultSize int) *Page {
 	return m
 }
 
+// NewCursorPageFromCtx 从请求参数 cursor 和 size 中读取游标分页参数, 排序由 sorts 指定
+func NewCursorPageFromCtx(ctx *gin.Context, defaultSize int, sorts ...database.Sort) database.CursorPage {
+	m := &CursorPage{}
+	ctx.ShouldBindQuery(m)
+	if m.Size <= 0 {
+		m.Size = defaultSize
+	}
+	return database.CursorPage{
+		Cursor: m.Cursor,
+		Size:   m.Size,
+		Sorts:  sorts,
+	}
+}
+
+type CursorPage struct {
+	Cursor string `form:"cursor"`
+	Size   int    `form:"size"`
+}
+
 type Page struct {
 	Page int `form:"page"`
 	Size int `form:"size"`
@@ -44,6 +64,8 @@ var operaters = [...]string{
 	"<",  //小于
 }
 
+// NewFiltersFromCtx 从 filters[field]=value 形式的请求参数中读取过滤条件.
+// 请求参数不能要求查询软删除的记录, 需要时由 handler 设置 Filters.Trashed
 func NewFiltersFromCtx(ctx *gin.Context) *Filters {
 	tmp, _ := get(ctx.Request.URL.Query(), "filters")
 	for k, v := range tmp {
@@ -70,10 +92,15 @@ type CustomerParser struct {
 
 type Filters struct {
 	M map[string][]string
+	// Trashed 是否包含软删除的记录, 只能由 handler 显式设置
+	Trashed database.Trashed
 }
 
 func (f Filters) ParseAll(customerParsers ...CustomerParser) (results []any) {
-	results = make([]any, 0, len(f.M))
+	results = make([]any, 0, len(f.M)+1)
+	if f.Trashed != database.WithoutTrashed {
+		results = append(results, f.Trashed)
+	}
 BIGLOOP:
 	for key, value := range f.M {
 		for _, parser := range customerParsers {
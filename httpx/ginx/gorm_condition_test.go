@@ -1,9 +1,12 @@
 package ginx
 
 import (
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
 	"gorm.io/gorm"
 	"gorm.io/gorm/utils/tests"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -46,6 +49,14 @@ func TestPage(t *testing.T) {
 	})
 }
 
+func TestCursorPage(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/?cursor=abc", nil)
+
+	p := NewCursorPageFromCtx(ctx, 20, database.Desc("created_at"))
+	require.Equal(t, database.CursorPage{Cursor: "abc", Size: 20, Sorts: []database.Sort{database.Desc("created_at")}}, p)
+}
+
 func TestFilters(t *testing.T) {
 	type test struct{}
 	f := Filters{
@@ -69,6 +80,19 @@ func TestFilters(t *testing.T) {
 	require.Contains(t, result, []any{"g", "7"})
 }
 
+func TestFiltersTrashed(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/?filters[trashed]=only&filters[userName]=a", nil)
+
+	// 请求参数中的 trashed 只是普通的字段过滤
+	f := NewFiltersFromCtx(ctx)
+	require.Equal(t, database.WithoutTrashed, f.Trashed)
+	require.Equal(t, map[string][]string{"trashed": {"only"}, "user_name": {"a"}}, f.M)
+
+	f.Trashed = database.OnlyTrashed
+	require.ElementsMatch(t, []any{database.OnlyTrashed, []any{"trashed", "only"}, []any{"user_name", "a"}}, f.ParseAll())
+}
+
 func TestSorts(t *testing.T) {
 	type test struct{}
 	s := Sorts{
@@ -1,11 +1,14 @@
 package ginx
 
 import (
+	"context"
 	"errors"
 	"github.com/gin-gonic/gin/binding"
 	jwt2 "github.com/golang-jwt/jwt/v5"
 	"github.com/zeddy-go/zeddy/convert"
+	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
+	"log/slog"
 	"reflect"
 
 	"github.com/gin-gonic/gin"
@@ -15,9 +18,51 @@ import (
 
 type NewResponseFunc func() IResponse[*gin.Context]
 
+const containerKey = "zeddy:container"
+
+var cursorType = reflect.TypeOf(database.Cursor{})
+
+// withScope 为每个请求创建 c 的子作用域并放入请求上下文, handler 的参数从该作用域中解析.
+// 作用域中绑定了 *gin.Context 和 context.Context, 请求结束时作用域被关闭
+func withScope(c *container.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope := c.NewScope()
+		_ = container.BindIn[*gin.Context](scope, ctx)
+		_ = container.BindIn[context.Context](scope, func() context.Context {
+			return ctx.Request.Context()
+		}, container.NoSingleton())
+		ctx.Set(containerKey, scope)
+
+		defer func() {
+			if err := scope.Close(); err != nil {
+				slog.ErrorContext(ctx.Request.Context(), "close request scope failed", "error", err)
+			}
+		}()
+
+		ctx.Next()
+	}
+}
+
+// Scope 返回请求的作用域容器, 没有时返回默认容器
+func Scope(ctx *gin.Context) *container.Container {
+	return containerFromCtx(ctx)
+}
+
+// containerFromCtx 获取请求上下文中的容器, 没有时使用默认容器
+func containerFromCtx(ctx *gin.Context) *container.Container {
+	if v, ok := ctx.Get(containerKey); ok {
+		if c, ok := v.(*container.Container); ok {
+			return c
+		}
+	}
+
+	return container.Default()
+}
+
 var defaultNewResponseFunc NewResponseFunc = NewRestfulResponse
 
 func GinMiddleware(f any) gin.HandlerFunc {
+	f, keys := container.UnwrapKeyed(f)
 	fType := reflect.TypeOf(f)
 	if fType.Kind() != reflect.Func {
 		panic(errors.New("func only"))
@@ -28,7 +73,7 @@ func GinMiddleware(f any) gin.HandlerFunc {
 	}
 
 	return func(ctx *gin.Context) {
-		params, err := buildParams(fType, ctx)
+		params, err := buildParams(fType, ctx, keys)
 		if err != nil {
 			parseAndResponse(reflect.ValueOf(errx.Wrap(err, "build params failed", errx.WithAbort())))
 			return
@@ -49,7 +94,9 @@ func GinMiddleware(f any) gin.HandlerFunc {
 	}
 }
 
+// GinHandler 将 f 包装为 gin 的 handler, f 可以是 container.Keyed 返回的 KeyedFunc, 指定键的参数只从容器中解析
 func GinHandler(f any) gin.HandlerFunc {
+	f, keys := container.UnwrapKeyed(f)
 	fType := reflect.TypeOf(f)
 	if fType.Kind() != reflect.Func {
 		panic(errors.New("func only"))
@@ -58,13 +105,13 @@ func GinHandler(f any) gin.HandlerFunc {
 	if fType.NumOut() > 3 {
 		panic(errors.New("should not return results more than 3"))
 	} else if fType.NumOut() == 3 {
-		if !isNumber(fType.Out(0)) && fType.Out(0).Name() != "IMeta" {
-			panic(errors.New("first one of results should be number(total of records) or IMeta"))
+		if !isNumber(fType.Out(0)) && fType.Out(0).Name() != "IMeta" && fType.Out(0) != cursorType {
+			panic(errors.New("first one of results should be number(total of records), database.Cursor or IMeta"))
 		}
 	}
 
 	return func(ctx *gin.Context) {
-		params, err := buildParams(fType, ctx)
+		params, err := buildParams(fType, ctx, keys)
 		if err != nil {
 			parseAndResponse(reflect.ValueOf(err)).Do(ctx)
 			return
@@ -76,10 +123,18 @@ func GinHandler(f any) gin.HandlerFunc {
 	}
 }
 
-func buildParams(fType reflect.Type, ctx *gin.Context) (params []reflect.Value, err error) {
+func buildParams(fType reflect.Type, ctx *gin.Context, keys map[int]string) (params []reflect.Value, err error) {
 	params = make([]reflect.Value, fType.NumIn())
 	valueCtx := reflect.ValueOf(ctx)
 	for i := 0; i < fType.NumIn(); i++ {
+		if key, ok := keys[i]; ok {
+			params[i], err = containerFromCtx(ctx).Resolve(fType.In(i), container.WithResolveKey(key))
+			if err != nil {
+				return
+			}
+			continue
+		}
+
 		switch fType.In(i) {
 		case valueCtx.Type():
 			params[i] = valueCtx
@@ -108,7 +163,7 @@ func buildParams(fType reflect.Type, ctx *gin.Context) (params []reflect.Value,
 }
 
 func parseParam(ctx *gin.Context, t reflect.Type) (p reflect.Value, err error) {
-	p, err = container.Default().Resolve(t)
+	p, err = containerFromCtx(ctx).Resolve(t)
 
 	if err != nil && !errors.Is(err, container.ErrNotFound) {
 		return
@@ -190,6 +245,8 @@ func parseAndResponse(results ...reflect.Value) (resp IResponse[*gin.Context]) {
 				panic(err)
 			}
 			resp = defaultNewResponseFunc().SetData(results[1].Interface()).SetMeta(&Meta{Total: uint(tmp.Interface().(int))})
+		} else if c, ok := results[0].Interface().(database.Cursor); ok {
+			resp = defaultNewResponseFunc().SetData(results[1].Interface()).SetMeta(&Meta{Cursor: &c})
 		} else if m, ok := results[0].Interface().(IMeta); ok {
 			resp = defaultNewResponseFunc().SetData(results[1].Interface()).SetMeta(m)
 		} else {
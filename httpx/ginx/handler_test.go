@@ -4,6 +4,8 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/database"
 	"net/http/httptest"
 	"reflect"
 	"strings"
@@ -97,4 +99,72 @@ func TestResponse(t *testing.T) {
 	require.Nil(t, r.err)
 	require.NotNil(t, r.Data)
 	require.NotNil(t, r.meta)
+
+	r = parseAndResponse(reflect.ValueOf(database.Cursor{Next: "next"}), reflect.ValueOf(gin.H{"test": true}), reflect.ValueOf(nil)).(*RestfulResponse)
+	require.Nil(t, r.err)
+	require.Equal(t, map[string]any{"nextCursor": "next", "prevCursor": ""}, r.meta.GetMeta())
+}
+
+func TestScope(t *testing.T) {
+	type currentUser struct {
+		Name   string
+		closed bool
+	}
+	gin.SetMode(gin.ReleaseMode)
+	c := container.NewContainer()
+	var users []*currentUser
+	require.NoError(t, container.BindIn[*currentUser](c, func(ctx *gin.Context) *currentUser {
+		u := &currentUser{Name: ctx.GetHeader("X-User")}
+		users = append(users, u)
+		return u
+	}, container.Scoped(), container.OnDispose(func(u *currentUser) {
+		u.closed = true
+	})))
+
+	r := gin.New()
+	r.Use(withScope(c))
+	r.GET("/me", GinHandler(func(u *currentUser, again *currentUser) (string, error) {
+		require.Same(t, u, again)
+		require.False(t, u.closed)
+		return u.Name, nil
+	}))
+
+	for _, name := range []string{"a", "b"} {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/me", nil)
+		request.Header.Set("X-User", name)
+		r.ServeHTTP(w, request)
+		require.Equal(t, 200, w.Code)
+		require.Contains(t, w.Body.String(), name)
+	}
+
+	require.Len(t, users, 2)
+	require.True(t, users[0].closed)
+	require.True(t, users[1].closed)
+}
+
+func TestKeyedHandler(t *testing.T) {
+	type db struct{ Name string }
+	gin.SetMode(gin.ReleaseMode)
+	c := container.NewContainer()
+	require.NoError(t, container.BindIn[*db](c, &db{Name: "primary"}, container.WithKey("primary")))
+	require.NoError(t, container.BindIn[*db](c, &db{Name: "analytics"}, container.WithKey("analytics")))
+
+	r := gin.New()
+	r.Use(withScope(c))
+	r.GET("/dbs", GinHandler(container.Keyed(func(primary *db, analytics *db) (string, error) {
+		return primary.Name + "," + analytics.Name, nil
+	}, map[int]string{0: "primary", 1: "analytics"})))
+	r.GET("/missing", GinHandler(container.Keyed(func(d *db) (string, error) {
+		return d.Name, nil
+	}, map[int]string{0: "report"})))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/dbs", nil))
+	require.Equal(t, 200, w.Code)
+	require.Contains(t, w.Body.String(), "primary,analytics")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	require.NotEqual(t, 200, w.Code)
 }
@@ -0,0 +1,31 @@
+package ginx
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/zeddy-go/zeddy/health"
+	"net/http"
+)
+
+// WithoutHealth 不注册 /healthz 和 /readyz 路由
+func WithoutHealth() func(*Module) {
+	return func(module *Module) {
+		module.withoutHealth = true
+	}
+}
+
+func registerHealth(router gin.IRouter, registry *health.Registry) {
+	router.GET("/healthz", healthHandler(registry.Liveness))
+	router.GET("/readyz", healthHandler(registry.Readiness))
+}
+
+func healthHandler(check func(ctx context.Context) health.Report) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := check(ctx.Request.Context())
+		if report.IsUp() {
+			ctx.JSON(http.StatusOK, report)
+		} else {
+			ctx.JSON(http.StatusServiceUnavailable, report)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	registry := health.NewRegistry()
+	registerHealth(r, registry)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, get("/healthz").Code)
+	require.Equal(t, http.StatusServiceUnavailable, get("/readyz").Code)
+
+	registry.SetReady(true)
+	w := get("/readyz")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"status":"up","checks":[]}`, w.Body.String())
+}
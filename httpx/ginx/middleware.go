@@ -1,6 +1,9 @@
 package ginx
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zeddy-go/zeddy/logx"
+)
 
 func CORS(c *gin.Context) {
 	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -15,3 +18,21 @@ func CORS(c *gin.Context) {
 
 	c.Next()
 }
+
+// RequestID 从 X-Request-Id 头读取请求 ID, 没有时生成一个, 并从 traceparent 头读取链路 ID.
+// 两者被放入请求的 ctx 中, 使用该 ctx 输出的日志会带上 request_id 和 trace_id
+func RequestID(c *gin.Context) {
+	id := c.GetHeader("X-Request-Id")
+	if id == "" {
+		id = logx.NewID()
+	}
+	c.Header("X-Request-Id", id)
+
+	ctx := logx.WithRequestID(c.Request.Context(), id)
+	if traceID := logx.ParseTraceParent(c.GetHeader("traceparent")); traceID != "" {
+		ctx = logx.WithTraceID(ctx, traceID)
+	}
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
@@ -2,13 +2,15 @@ package ginx
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
 	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/configx"
 	"github.com/zeddy-go/zeddy/container"
-	"log/slog"
+	"github.com/zeddy-go/zeddy/errx"
+	"net"
 	"net/http"
-	"time"
+	"sync/atomic"
 )
 
 func WithCustomEngine(e *gin.Engine) func(*Module) {
@@ -17,6 +19,7 @@ func WithCustomEngine(e *gin.Engine) func(*Module) {
 	}
 }
 
+// WithPrefix 指定配置前缀, 默认没有前缀; 指定前缀时模块名称为 "ginx.<prefix>", 以便同一应用中使用多个实例
 func WithPrefix(prefix string) func(*Module) {
 	return func(module *Module) {
 		module.prefix = prefix
@@ -24,14 +27,19 @@ func WithPrefix(prefix string) func(*Module) {
 }
 
 func NewModule(opts ...func(*Module)) *Module {
-	m := &Module{}
+	m := &Module{
+		routes: &routeTable{},
+	}
 
 	for _, set := range opts {
 		set(m)
 	}
 
 	if m.router == nil {
-		m.router = gin.Default()
+		e := gin.Default()
+		// 使 *gin.Context 可以作为 ctx 读取请求 ctx 中的值, 如日志使用的请求 ID
+		e.ContextWithFallback = true
+		m.router = e
 	}
 
 	return m
@@ -39,24 +47,76 @@ func NewModule(opts ...func(*Module)) *Module {
 
 type Module struct {
 	app.IsModule
-	prefix string
-	router gin.IRouter
-	svr    *http.Server
+	prefix        string
+	router        gin.IRouter
+	svr           *http.Server
+	withoutHealth bool
+	routes        *routeTable
+	cors          atomic.Bool
+}
+
+// Name 没有前缀时为 "ginx", 否则为 "ginx.<prefix>"
+func (m *Module) Name() string {
+	if m.prefix == "" {
+		return "ginx"
+	}
+
+	return "ginx." + m.prefix
+}
+
+func (m *Module) Dependencies() []string {
+	return []string{"configx"}
+}
+
+// Config http 服务配置
+type Config struct {
+	Addr     string `mapstructure:"addr" validate:"required"`
+	Cors     bool   `mapstructure:"cors"`
+	Lts      bool   `mapstructure:"lts"`
+	CertFile string `mapstructure:"certFile" validate:"required_if=Lts true"`
+	KeyFile  string `mapstructure:"keyFile" validate:"required_if=Lts true"`
+}
+
+func (m *Module) config() (Config, error) {
+	return container.ResolveIn[Config](m.Container())
+}
+
+// key 返回模块配置的完整键名
+func (m *Module) key(name string) string {
+	if m.prefix == "" {
+		return name
+	}
+
+	return m.prefix + "." + name
 }
 
 func (m *Module) Init() (err error) {
-	var c *viper.Viper
-	if m.prefix != "" {
-		c = viper.Sub(m.prefix)
-	} else {
-		c = viper.GetViper()
+	err = configx.Bind[Config](m.Container(), m.prefix)
+	if err != nil {
+		return
+	}
+
+	m.router.Use(RequestID, withScope(m.Container()))
+
+	// CORS 中间件总是注册, 是否生效由配置决定, 以便配置重新加载时切换
+	m.router.Use(func(ctx *gin.Context) {
+		if m.cors.Load() {
+			CORS(ctx)
+		} else {
+			ctx.Next()
+		}
+	})
+	if cm, e := container.ResolveIn[*configx.Module](m.Container()); e == nil {
+		configx.Subscribe(cm, m.key("cors"), func(enabled bool) {
+			m.cors.Store(enabled)
+		})
 	}
 
-	if c.GetBool("cors") {
-		m.router.Use(CORS)
+	if !m.withoutHealth {
+		registerHealth(m.router, m.App().Health())
 	}
 
-	err = container.Bind[Router](m)
+	err = container.BindIn[Router](m.Container(), m)
 	if err != nil {
 		return
 	}
@@ -64,50 +124,72 @@ func (m *Module) Init() (err error) {
 	return
 }
 
+// Boot 读取初始配置, 配置已在 configx 模块 Boot 时校验
+func (m *Module) Boot() (err error) {
+	c, err := m.config()
+	if err != nil {
+		return
+	}
+	m.cors.Store(c.Cors)
+
+	return
+}
+
 func (m *Module) Any(route string, handler any, middlewares ...any) Router {
 	m.router.Any(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "ANY", route, handler)
 	return m
 }
 
 func (m *Module) GET(route string, handler any, middlewares ...any) Router {
 	m.router.GET(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "GET", route, handler)
 	return m
 }
 
 func (m *Module) POST(route string, handler any, middlewares ...any) Router {
 	m.router.POST(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "POST", route, handler)
 	return m
 }
 
 func (m *Module) DELETE(route string, handler any, middlewares ...any) Router {
 	m.router.DELETE(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "DELETE", route, handler)
 	return m
 }
 
 func (m *Module) PATCH(route string, handler any, middlewares ...any) Router {
 	m.router.PATCH(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "PATCH", route, handler)
 	return m
 }
 
 func (m *Module) PUT(route string, handler any, middlewares ...any) Router {
 	m.router.PUT(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "PUT", route, handler)
 	return m
 }
 
 func (m *Module) OPTIONS(route string, handler any, middlewares ...any) Router {
 	m.router.OPTIONS(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "OPTIONS", route, handler)
 	return m
 }
 
 func (m *Module) HEAD(route string, handler any, middlewares ...any) Router {
 	m.router.HEAD(route, m.wrap(handler, middlewares...)...)
+	m.routes.add(m.router, "HEAD", route, handler)
 	return m
 }
 
 func (m *Module) Group(prefix string, middlewares ...any) Router {
 	group := m.router.Group(prefix, m.wrap(nil, middlewares...)...)
 	return &Module{
-		router: group,
+		IsModule: m.IsModule,
+		prefix:   m.prefix,
+		router:   group,
+		routes:   m.routes,
 	}
 }
 
@@ -129,32 +211,38 @@ func (m *Module) wrap(handler any, middlewares ...any) (handlers []gin.HandlerFu
 	return
 }
 
-func (m *Module) Start() {
-	var c *viper.Viper
-	if m.prefix != "" {
-		c = viper.Sub(m.prefix)
-	} else {
-		c = viper.GetViper()
+func (m *Module) Start(ctx context.Context, ready func()) (err error) {
+	c, err := m.config()
+	if err != nil {
+		return
 	}
 
 	m.svr = &http.Server{
 		Handler: m.router.(http.Handler),
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	m.svr.Addr = c.Addr
+
+	lis, err := net.Listen("tcp", m.svr.Addr)
+	if err != nil {
+		return errx.Wrap(err, "tcp listen port failed")
 	}
-	m.svr.Addr = c.GetString("addr")
+	ready()
 
-	var err error
-	if c.GetBool("lts") {
-		err = m.svr.ListenAndServeTLS(c.GetString("certFile"), c.GetString("keyFile"))
+	if c.Lts {
+		err = m.svr.ServeTLS(lis, c.CertFile, c.KeyFile)
 	} else {
-		err = m.svr.ListenAndServe()
+		err = m.svr.Serve(lis)
 	}
-	if err != nil {
-		slog.Info("server shutdown", "error", err)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
 	}
+
+	return
 }
 
-func (m *Module) Stop() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	_ = m.svr.Shutdown(ctx)
+func (m *Module) Stop(ctx context.Context) error {
+	return m.svr.Shutdown(ctx)
 }
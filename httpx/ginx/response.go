@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/zeddy-go/zeddy/convert"
+	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
 	"gorm.io/gorm"
 	"net/http"
@@ -72,6 +73,8 @@ type Meta struct {
 	Total       uint
 	LastPage    uint
 	PerPage     uint
+	// Cursor 游标分页的结果, 设置时输出 nextCursor 和 prevCursor, 不输出 total
+	Cursor *database.Cursor
 }
 
 func (m *Meta) GetMeta() (result map[string]any) {
@@ -82,7 +85,12 @@ func (m *Meta) GetMeta() (result map[string]any) {
 	if m.CurrentPage != 0 {
 		result["currentPage"] = m.CurrentPage
 	}
-	result["total"] = m.Total
+	if m.Cursor != nil {
+		result["nextCursor"] = m.Cursor.Next
+		result["prevCursor"] = m.Cursor.Prev
+	} else {
+		result["total"] = m.Total
+	}
 	if m.LastPage != 0 {
 		result["lastPage"] = m.LastPage
 	}
@@ -131,7 +139,8 @@ func (r *RestfulResponse) Do(ctx *gin.Context) {
 		var abort bool
 		message := r.err.Error()
 		status := http.StatusInternalServerError
-		if x, ok := r.err.(*errx.Errx); ok && http.StatusText(errx.GetErrxField[int](x, errx.Code)) != "" {
+		var x *errx.Errx
+		if errors.As(r.err, &x) && http.StatusText(errx.GetErrxField[int](x, errx.Code)) != "" {
 			status = errx.GetErrxField[int](x, errx.Code)
 			abort = errx.GetErrxField[bool](x, errx.Abort)
 		} else if _, ok := r.err.(validator.ValidationErrors); ok {
@@ -139,6 +148,8 @@ func (r *RestfulResponse) Do(ctx *gin.Context) {
 			//TODO: i18n and detail
 		} else if errors.Is(r.err, gorm.ErrRecordNotFound) {
 			status = http.StatusNotFound
+		} else if errors.Is(r.err, database.ErrVersionConflict) {
+			status = http.StatusConflict
 		}
 		r.Message = message
 		if abort {
@@ -2,9 +2,11 @@ package ginx
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/database"
 	"github.com/zeddy-go/zeddy/errx"
 	"net/http"
 	"net/http/httptest"
@@ -52,6 +54,9 @@ func TestJsonResponse(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, r.Code)
 	require.Equal(t, `{"data":null,"message":"test"}`, r.Body.String())
 
+	r = do(NewRestfulResponse().SetError(fmt.Errorf("update: %w", errx.Wrap(database.ErrVersionConflict, "test"))))
+	require.Equal(t, http.StatusConflict, r.Code)
+
 	type a struct {
 		A int `json:"a" binding:"required"`
 		B int `json:"b" binding:"required"`
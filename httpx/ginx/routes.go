@@ -0,0 +1,88 @@
+package ginx
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/container"
+)
+
+type RouteInfo struct {
+	Method  string
+	Path    string
+	Handler string
+}
+
+// routeTable 记录通过 Router 注册的路由, 同一个模块的分组共享同一个 routeTable
+type routeTable struct {
+	lock   sync.Mutex
+	routes []RouteInfo
+}
+
+func (r *routeTable) add(router gin.IRouter, method string, route string, handler any) {
+	if r == nil {
+		return
+	}
+
+	p := route
+	if x, ok := router.(interface{ BasePath() string }); ok {
+		p = path.Join(x.BasePath(), route)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routes = append(r.routes, RouteInfo{
+		Method:  method,
+		Path:    p,
+		Handler: funcName(handler),
+	})
+}
+
+func (r *routeTable) list() []RouteInfo {
+	if r == nil {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]RouteInfo(nil), r.routes...)
+}
+
+func funcName(f any) string {
+	f, _ = container.UnwrapKeyed(f)
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", f)
+	}
+
+	return runtime.FuncForPC(v.Pointer()).Name()
+}
+
+// Routes 返回通过 Router 注册的全部路由
+func (m *Module) Routes() []RouteInfo {
+	return m.routes.list()
+}
+
+func (m *Module) Commands() []*app.Command {
+	return []*app.Command{
+		{
+			Name:  "routes",
+			Usage: "list routes registered on the router",
+			Run: func(a *app.App, args []string) (err error) {
+				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				_, _ = fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+				for _, r := range m.Routes() {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Method, r.Path, r.Handler)
+				}
+				return w.Flush()
+			},
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testRouteHandler() {}
+
+func TestRoutes(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	m := NewModule(WithCustomEngine(gin.New()))
+	m.GET("/users", testRouteHandler)
+	m.Group("/v1").POST("/orders", testRouteHandler)
+
+	require.Equal(t, []RouteInfo{
+		{Method: "GET", Path: "/users", Handler: "github.com/zeddy-go/zeddy/httpx/ginx.testRouteHandler"},
+		{Method: "POST", Path: "/v1/orders", Handler: "github.com/zeddy-go/zeddy/httpx/ginx.testRouteHandler"},
+	}, m.Routes())
+}
@@ -3,13 +3,19 @@ package grpcx
 import (
 	"context"
 	"github.com/bufbuild/protovalidate-go"
+	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/errx"
+	"github.com/zeddy-go/zeddy/logx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
+	"log/slog"
 )
 
 func simpleInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (result any, err error) {
+	ctx = withLogIDs(ctx)
+
 	if req != nil {
 		var v *protovalidate.Validator
 		v, err = protovalidate.New()
@@ -26,3 +32,79 @@ func simpleInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySer
 	// 调用被拦截的方法
 	return handler(ctx, req)
 }
+
+// withLogIDs 从 metadata 读取请求 ID 和链路 ID 放入 ctx, 没有请求 ID 时生成一个
+func withLogIDs(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := first(md.Get("x-request-id"))
+	if id == "" {
+		id = logx.NewID()
+	}
+	ctx = logx.WithRequestID(ctx, id)
+	if traceID := logx.ParseTraceParent(first(md.Get("traceparent"))); traceID != "" {
+		ctx = logx.WithTraceID(ctx, traceID)
+	}
+
+	return ctx
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+type scopeKey struct{}
+
+// scopeInterceptor 为每个 rpc 创建容器的子作用域, 作用域中绑定了 rpc 的 context.Context, rpc 结束时作用域被关闭
+func (m *Module) scopeInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (result any, err error) {
+	ctx, closeScope := m.newScope(ctx, info.FullMethod)
+	defer closeScope()
+
+	return handler(ctx, req)
+}
+
+// simpleStreamInterceptor 与 simpleInterceptor 相同, 为流式 rpc 的 ctx 放入请求 ID 和链路 ID
+func simpleStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &serverStream{ServerStream: ss, ctx: withLogIDs(ss.Context())})
+}
+
+// streamScopeInterceptor 与 scopeInterceptor 相同, 作用域在流结束时被关闭
+func (m *Module) streamScopeInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, closeScope := m.newScope(ss.Context(), info.FullMethod)
+	defer closeScope()
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// newScope 创建 rpc 的作用域并放入 ctx, 返回的函数用于关闭作用域
+func (m *Module) newScope(ctx context.Context, method string) (context.Context, func()) {
+	scope := m.Container().NewScope()
+	ctx = context.WithValue(ctx, scopeKey{}, scope)
+	_ = container.BindIn[context.Context](scope, ctx)
+
+	return ctx, func() {
+		if e := scope.Close(); e != nil {
+			slog.ErrorContext(ctx, "close rpc scope failed", "method", method, "error", e)
+		}
+	}
+}
+
+// serverStream 替换 grpc.ServerStream 的 ctx
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// Scope 返回 rpc 的作用域容器, 不在 rpc 中时返回 nil
+func Scope(ctx context.Context) *container.Container {
+	scope, _ := ctx.Value(scopeKey{}).(*container.Container)
+	return scope
+}
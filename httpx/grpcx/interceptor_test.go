@@ -0,0 +1,71 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/container"
+	"github.com/zeddy-go/zeddy/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	type session struct{ closed bool }
+
+	a := app.NewApp()
+	m := NewModule()
+	a.Use(m)
+	var sessions []*session
+	require.NoError(t, container.BindIn[*session](m.Container(), func() *session {
+		s := &session{}
+		sessions = append(sessions, s)
+		return s
+	}, container.Scoped(), container.OnDispose(func(s *session) {
+		s.closed = true
+	})))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "abc"))
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+	interceptor := func(srv any, ss grpc.ServerStream, handler grpc.StreamHandler) error {
+		return simpleStreamInterceptor(srv, ss, info, func(srv any, ss grpc.ServerStream) error {
+			return m.streamScopeInterceptor(srv, ss, info, handler)
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		err := interceptor(nil, &testStream{ctx: ctx}, func(srv any, ss grpc.ServerStream) error {
+			require.Equal(t, "abc", logx.RequestID(ss.Context()))
+
+			scope := Scope(ss.Context())
+			require.NotNil(t, scope)
+			c, err := container.ResolveIn[context.Context](scope)
+			require.NoError(t, err)
+			require.Equal(t, ss.Context(), c)
+
+			s, err := container.ResolveIn[*session](scope)
+			require.NoError(t, err)
+			again, err := container.ResolveIn[*session](scope)
+			require.NoError(t, err)
+			require.Same(t, s, again)
+			require.False(t, s.closed)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, sessions, 2)
+	require.True(t, sessions[0].closed)
+	require.True(t, sessions[1].closed)
+}
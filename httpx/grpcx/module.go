@@ -1,18 +1,24 @@
 package grpcx
 
 import (
-	"github.com/spf13/viper"
+	"context"
+	"errors"
 	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/configx"
 	"github.com/zeddy-go/zeddy/container"
 	"github.com/zeddy-go/zeddy/errx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
-	"log/slog"
 	"net"
+	"time"
 )
 
+// defaultPrefix 默认的配置前缀
+const defaultPrefix = "grpc"
+
+// WithPrefix 指定配置前缀, 与默认前缀不同时模块名称为 "grpcx.<prefix>", 以便同一应用中使用多个实例
 func WithPrefix(prefix string) func(*Module) {
 	return func(module *Module) {
 		module.prefix = prefix
@@ -21,7 +27,7 @@ func WithPrefix(prefix string) func(*Module) {
 
 func NewModule(opts ...func(*Module)) *Module {
 	m := &Module{
-		prefix: "grpc",
+		prefix: defaultPrefix,
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -31,52 +37,133 @@ func NewModule(opts ...func(*Module)) *Module {
 
 type Module struct {
 	app.IsModule
-	grpcServer *grpc.Server
-	prefix     string
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	prefix       string
+}
+
+// Name 使用默认前缀时为 "grpcx", 否则为 "grpcx.<prefix>"
+func (m *Module) Name() string {
+	if m.prefix == defaultPrefix {
+		return "grpcx"
+	}
+
+	return "grpcx." + m.prefix
+}
+
+func (m *Module) Dependencies() []string {
+	return []string{"configx"}
+}
+
+// Config grpc 服务配置
+type Config struct {
+	Addr       string `mapstructure:"addr" validate:"required"`
+	Reflection bool   `mapstructure:"reflection"`
+	// HealthInterval 同步健康状态的间隔
+	HealthInterval time.Duration `mapstructure:"healthInterval" default:"5s" validate:"gt=0"`
+}
+
+func (m *Module) config() (Config, error) {
+	return container.ResolveIn[Config](m.Container())
 }
 
 func (m *Module) Init() (err error) {
-	c := viper.Sub(m.prefix)
+	err = configx.Bind[Config](m.Container(), m.prefix)
+	if err != nil {
+		return
+	}
 
 	m.grpcServer = grpc.NewServer(
-		grpc.UnaryInterceptor(simpleInterceptor),
+		grpc.ChainUnaryInterceptor(simpleInterceptor, m.scopeInterceptor),
+		grpc.ChainStreamInterceptor(simpleStreamInterceptor, m.streamScopeInterceptor),
 	)
 
-	healthCheck := health.NewServer()
-	healthgrpc.RegisterHealthServer(m.grpcServer, healthCheck)
+	m.healthServer = health.NewServer()
+	m.healthServer.SetServingStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	healthgrpc.RegisterHealthServer(m.grpcServer, m.healthServer)
 
-	err = container.Bind[*health.Server](healthCheck)
+	err = container.BindIn[*health.Server](m.Container(), m.healthServer)
 	if err != nil {
 		return
 	}
 
-	if c.GetBool("reflection") {
-		reflection.Register(m.grpcServer)
+	err = container.BindIn[*grpc.Server](m.Container(), m.grpcServer)
+	if err != nil {
+		return
 	}
 
-	err = container.Bind[*grpc.Server](m.grpcServer)
+	return
+}
+
+func (m *Module) Boot() (err error) {
+	c, err := m.config()
 	if err != nil {
 		return
 	}
 
+	if c.Reflection {
+		reflection.Register(m.grpcServer)
+	}
+
 	return
 }
 
-func (m *Module) Start() {
-	c := viper.Sub(m.prefix)
+func (m *Module) Start(ctx context.Context, ready func()) (err error) {
+	c, err := m.config()
+	if err != nil {
+		return
+	}
 
-	var lis net.Listener
-	lis, err := net.Listen("tcp", c.GetString("addr"))
+	lis, err := net.Listen("tcp", c.Addr)
 	if err != nil {
-		panic(errx.Wrap(err, "tcp listen port failed"))
+		return errx.Wrap(err, "tcp listen port failed")
 	}
+	ready()
+
+	go m.syncHealth(ctx, c.HealthInterval)
 
 	err = m.grpcServer.Serve(lis)
-	if err != nil {
-		slog.Error("grpc server shutdown", "error", err)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		err = nil
 	}
+
+	return
 }
 
-func (m *Module) Stop() {
-	m.grpcServer.Stop()
+// syncHealth 定期将应用的就绪状态同步到 grpc 健康检查服务
+func (m *Module) syncHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthgrpc.HealthCheckResponse_SERVING
+		if !m.App().Health().Readiness(ctx).IsUp() {
+			status = healthgrpc.HealthCheckResponse_NOT_SERVING
+		}
+		m.healthServer.SetServingStatus("", status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Module) Stop(ctx context.Context) error {
+	m.healthServer.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.grpcServer.GracefulStop()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		m.grpcServer.Stop()
+	}
+
+	return nil
 }
@@ -0,0 +1,51 @@
+package logx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+type traceIDKey struct{}
+
+// WithRequestID 将请求 ID 放入 ctx, 使用 ctx 输出的日志会带上 request_id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 返回 ctx 中的请求 ID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTraceID 将链路 ID 放入 ctx, 使用 ctx 输出的日志会带上 trace_id
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID 返回 ctx 中的链路 ID
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// NewID 生成随机的请求 ID
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParseTraceParent 从 W3C traceparent 头中解析链路 ID, 格式为 version-traceid-parentid-flags
+func ParseTraceParent(header string) string {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+
+	return parts[1]
+}
@@ -0,0 +1,196 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// contextHandler 为日志附加 ctx 中的请求 ID 和链路 ID
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if id := TraceID(ctx); id != "" {
+			r.AddAttrs(slog.String("trace_id", id))
+		}
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type packageLevel struct {
+	pkg   string
+	level slog.Level
+}
+
+type levelSet struct {
+	def slog.Level
+	min slog.Level
+	// packages 按包名长度倒序, 优先匹配最具体的包
+	packages []packageLevel
+}
+
+// Levels 默认日志级别和按包设置的日志级别, 可以在运行时修改
+type Levels struct {
+	v     atomic.Pointer[levelSet]
+	cache sync.Map
+}
+
+func newLevels(def slog.Level, packages map[string]slog.Level) *Levels {
+	l := &Levels{}
+	l.Set(def, packages)
+	return l
+}
+
+// Set 修改默认日志级别和按包设置的日志级别
+func (l *Levels) Set(def slog.Level, packages map[string]slog.Level) {
+	set := &levelSet{def: def, min: def}
+	for pkg, level := range packages {
+		set.packages = append(set.packages, packageLevel{pkg: pkg, level: level})
+		set.min = min(set.min, level)
+	}
+	sort.Slice(set.packages, func(i, j int) bool {
+		return len(set.packages[i].pkg) > len(set.packages[j].pkg)
+	})
+	l.v.Store(set)
+}
+
+// Level 返回默认日志级别
+func (l *Levels) Level() slog.Level {
+	return l.v.Load().def
+}
+
+// levelOf 返回调用位置 pc 所在包的日志级别
+func (l *Levels) levelOf(pc uintptr) slog.Level {
+	set := l.v.Load()
+	if len(set.packages) == 0 || pc == 0 {
+		return set.def
+	}
+
+	pkg := l.packageOf(pc)
+	for _, p := range set.packages {
+		if pkg == p.pkg || strings.HasPrefix(pkg, p.pkg+"/") {
+			return p.level
+		}
+	}
+
+	return set.def
+}
+
+func (l *Levels) packageOf(pc uintptr) string {
+	if pkg, ok := l.cache.Load(pc); ok {
+		return pkg.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := packageName(frame.Function)
+	l.cache.Store(pc, pkg)
+
+	return pkg
+}
+
+// packageName 从函数全名中取出包名, 如 github.com/a/b.(*T).F 返回 github.com/a/b
+func packageName(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
+
+// levelHandler 按日志调用位置所在包的级别过滤日志
+type levelHandler struct {
+	slog.Handler
+	levels *Levels
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.v.Load().min
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.levelOf(r.PC) {
+		return nil
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{Handler: h.Handler.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{Handler: h.Handler.WithGroup(name), levels: h.levels}
+}
+
+// sampler 每个周期内相同级别和消息的日志只输出前 initial 条, 之后每 thereafter 条输出一条
+type sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+	lock       sync.Mutex
+	counts     map[string]int
+	reset      time.Time
+}
+
+func (s *sampler) allow(r slog.Record) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.Time.After(s.reset) {
+		s.counts = make(map[string]int)
+		s.reset = r.Time.Add(s.tick)
+	}
+
+	key := r.Level.String() + ":" + r.Message
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// samplingHandler 对日志采样, error 及以上级别的日志总是输出
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func (h samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelError && !h.sampler.allow(r) {
+		return nil
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h samplingHandler) WithGroup(name string) slog.Handler {
+	return samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
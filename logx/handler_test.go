@@ -0,0 +1,87 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T, c Config, def slog.Level) (*slog.Logger, *Levels, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	if c.Format == "" {
+		c.Format = FormatJSON
+	}
+	h, levels, err := NewHandler(c, buf, def)
+	require.NoError(t, err)
+	return slog.New(h), levels, buf
+}
+
+func lines(buf *bytes.Buffer) (result []map[string]any) {
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		_ = json.Unmarshal([]byte(line), &m)
+		result = append(result, m)
+	}
+	return
+}
+
+func TestPackageLevels(t *testing.T) {
+	logger, levels, buf := newTestLogger(t, Config{
+		Packages: []PackageConfig{{Name: "github.com/zeddy-go/zeddy/logx", Level: "debug"}},
+	}, slog.LevelError)
+
+	logger.Debug("debug")
+	require.Len(t, lines(buf), 1)
+
+	levels.Set(slog.LevelDebug, map[string]slog.Level{"github.com/zeddy-go/zeddy": slog.LevelWarn})
+	logger.Info("info")
+	require.Len(t, lines(buf), 1)
+
+	levels.Set(slog.LevelError, map[string]slog.Level{"github.com/zeddy-go/zeddy/log": slog.LevelDebug})
+	logger.Warn("warn")
+	require.Len(t, lines(buf), 1)
+}
+
+func TestContextIDs(t *testing.T) {
+	logger, _, buf := newTestLogger(t, Config{}, slog.LevelInfo)
+
+	ctx := WithTraceID(WithRequestID(context.Background(), "req"), "trace")
+	logger.InfoContext(ctx, "hello")
+
+	l := lines(buf)
+	require.Len(t, l, 1)
+	require.Equal(t, "req", l[0]["request_id"])
+	require.Equal(t, "trace", l[0]["trace_id"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+}
+
+func TestSampling(t *testing.T) {
+	logger, _, buf := newTestLogger(t, Config{
+		Sampling: SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Minute},
+	}, slog.LevelInfo)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("repeated")
+	}
+	logger.Info("other")
+	logger.Error("error")
+	logger.Error("error")
+
+	// 前 2 条, 之后第 5, 8 条
+	l := lines(buf)
+	require.Len(t, l, 7)
+}
+
+func TestPackageName(t *testing.T) {
+	require.Equal(t, "github.com/zeddy-go/zeddy/logx", packageName("github.com/zeddy-go/zeddy/logx.(*Levels).Set"))
+	require.Equal(t, "main", packageName("main.main"))
+}
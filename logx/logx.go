@@ -0,0 +1,133 @@
+package logx
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zeddy-go/zeddy/errx"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config 日志配置
+type Config struct {
+	// Format 输出格式, text 或 json
+	Format string `mapstructure:"format" default:"json" validate:"oneof=text json"`
+	// Level 默认日志级别, 为空时 local 模式或开启 showDebugLog 时为 debug, 否则为 info
+	Level string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error"`
+	// Packages 按包设置日志级别, 以调用日志的代码所在的包匹配, 子包同样生效
+	Packages  []PackageConfig `mapstructure:"packages" validate:"dive"`
+	AddSource bool            `mapstructure:"addSource" default:"true"`
+	// Output 输出位置, stdout, stderr 或文件路径, 文件会按 Rotate 配置切割
+	Output   string         `mapstructure:"output" default:"stdout" validate:"required"`
+	Rotate   RotateConfig   `mapstructure:"rotate"`
+	Sampling SamplingConfig `mapstructure:"sampling"`
+}
+
+type PackageConfig struct {
+	Name  string `mapstructure:"name" validate:"required"`
+	Level string `mapstructure:"level" validate:"oneof=debug info warn error"`
+}
+
+// RotateConfig 日志文件切割配置
+type RotateConfig struct {
+	// MaxSize 单个文件的最大大小, 单位 MB
+	MaxSize int `mapstructure:"maxSize" default:"100" validate:"gte=0"`
+	// MaxAge 旧文件保留的天数, 为 0 时不按时间清理
+	MaxAge int `mapstructure:"maxAge" validate:"gte=0"`
+	// MaxBackups 旧文件保留的个数, 为 0 时不按个数清理
+	MaxBackups int  `mapstructure:"maxBackups" validate:"gte=0"`
+	Compress   bool `mapstructure:"compress"`
+}
+
+// SamplingConfig 日志采样配置, Initial 为 0 时不采样
+type SamplingConfig struct {
+	// Initial 每个周期内相同级别和消息的日志全部输出的条数
+	Initial int `mapstructure:"initial" validate:"gte=0"`
+	// Thereafter 超过 Initial 后每 Thereafter 条输出一条, 为 0 时不再输出
+	Thereafter int           `mapstructure:"thereafter" validate:"gte=0"`
+	Tick       time.Duration `mapstructure:"tick" default:"1s" validate:"gt=0"`
+}
+
+// ParseLevel 解析日志级别名称
+func ParseLevel(name string) (level slog.Level, err error) {
+	err = level.UnmarshalText([]byte(strings.ToLower(name)))
+	if err != nil {
+		err = errx.Wrap(err, "invalid log level")
+	}
+	return
+}
+
+// packageLevels 解析按包设置的日志级别
+func (c Config) packageLevels() (levels map[string]slog.Level, err error) {
+	levels = make(map[string]slog.Level, len(c.Packages))
+	for _, p := range c.Packages {
+		levels[p.Name], err = ParseLevel(p.Level)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// NewWriter 根据配置创建日志输出
+func NewWriter(c Config) io.Writer {
+	switch c.Output {
+	case "", "stdout":
+		return os.Stdout
+	case "stderr":
+		return os.Stderr
+	default:
+		return &lumberjack.Logger{
+			Filename:   c.Output,
+			MaxSize:    c.Rotate.MaxSize,
+			MaxAge:     c.Rotate.MaxAge,
+			MaxBackups: c.Rotate.MaxBackups,
+			Compress:   c.Rotate.Compress,
+			LocalTime:  true,
+		}
+	}
+}
+
+// NewHandler 根据配置创建日志处理器, def 为默认日志级别, 返回的 Levels 可以在运行时修改日志级别
+func NewHandler(c Config, w io.Writer, def slog.Level) (h slog.Handler, levels *Levels, err error) {
+	packages, err := c.packageLevels()
+	if err != nil {
+		return
+	}
+	levels = newLevels(def, packages)
+
+	// 级别由 levelHandler 过滤, 这里不再过滤
+	opts := &slog.HandlerOptions{
+		AddSource: c.AddSource,
+		Level:     slog.Level(-1 << 10),
+	}
+	if c.Format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	if c.Sampling.Initial > 0 {
+		h = samplingHandler{
+			Handler: h,
+			sampler: &sampler{
+				initial:    c.Sampling.Initial,
+				thereafter: c.Sampling.Thereafter,
+				tick:       c.Sampling.Tick,
+			},
+		}
+	}
+	h = levelHandler{Handler: h, levels: levels}
+	h = contextHandler{Handler: h}
+
+	return
+}
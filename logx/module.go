@@ -0,0 +1,142 @@
+package logx
+
+import (
+	"log/slog"
+
+	"github.com/spf13/viper"
+	"github.com/zeddy-go/zeddy/app"
+	"github.com/zeddy-go/zeddy/configx"
+	"github.com/zeddy-go/zeddy/container"
+)
+
+// defaultPrefix 默认的配置前缀
+const defaultPrefix = "log"
+
+// WithPrefix 指定配置前缀, 与默认前缀不同时模块名称为 "logx.<prefix>"
+func WithPrefix(prefix string) func(*Module) {
+	return func(module *Module) {
+		module.prefix = prefix
+	}
+}
+
+func NewModule(opts ...func(*Module)) *Module {
+	m := &Module{
+		prefix: defaultPrefix,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Module 根据配置创建日志处理器并设置为 slog 的默认日志, 配置重新加载时会更新日志级别
+type Module struct {
+	app.IsModule
+	prefix string
+	levels *Levels
+}
+
+// Name 使用默认前缀时为 "logx", 否则为 "logx.<prefix>"
+func (m *Module) Name() string {
+	if m.prefix == defaultPrefix {
+		return "logx"
+	}
+
+	return "logx." + m.prefix
+}
+
+func (m *Module) Dependencies() []string {
+	return []string{"configx"}
+}
+
+// key 返回模块配置的完整键名
+func (m *Module) key(name string) string {
+	if m.prefix == "" {
+		return name
+	}
+
+	return m.prefix + "." + name
+}
+
+func (m *Module) Init() (err error) {
+	return configx.Bind[Config](m.Container(), m.prefix)
+}
+
+func (m *Module) Boot() (err error) {
+	c, err := container.ResolveIn[Config](m.Container())
+	if err != nil {
+		return
+	}
+	v, err := container.ResolveIn[*viper.Viper](m.Container())
+	if err != nil {
+		return
+	}
+
+	def, err := defaultLevel(v, c.Level)
+	if err != nil {
+		return
+	}
+	h, levels, err := NewHandler(c, NewWriter(c), def)
+	if err != nil {
+		return
+	}
+	m.levels = levels
+
+	logger := slog.New(h)
+	slog.SetDefault(logger)
+	err = container.BindIn[*slog.Logger](m.Container(), logger)
+	if err != nil {
+		return
+	}
+	err = container.BindIn[*Levels](m.Container(), levels)
+	if err != nil {
+		return
+	}
+
+	if cm, e := container.ResolveIn[*configx.Module](m.Container()); e == nil {
+		refresh := func() {
+			if e := m.refresh(cm.Viper()); e != nil {
+				slog.Error("refresh log level failed", "error", e)
+			}
+		}
+		configx.Subscribe(cm, "mode", func(string) { refresh() })
+		configx.Subscribe(cm, "showDebugLog", func(bool) { refresh() })
+		configx.Subscribe(cm, m.key("level"), func(string) { refresh() })
+		configx.Subscribe(cm, m.key("packages"), func([]PackageConfig) { refresh() })
+	}
+
+	return
+}
+
+// refresh 根据当前配置更新日志级别
+func (m *Module) refresh(v *viper.Viper) (err error) {
+	def, err := defaultLevel(v, v.GetString(m.key("level")))
+	if err != nil {
+		return
+	}
+
+	var c Config
+	err = v.UnmarshalKey(m.key("packages"), &c.Packages)
+	if err != nil {
+		return
+	}
+	packages, err := c.packageLevels()
+	if err != nil {
+		return
+	}
+	m.levels.Set(def, packages)
+
+	return
+}
+
+// defaultLevel 返回默认日志级别, 没有配置时 local 模式或开启 showDebugLog 时为 debug
+func defaultLevel(v *viper.Viper, level string) (slog.Level, error) {
+	if level != "" {
+		return ParseLevel(level)
+	}
+	if v.GetString("mode") == configx.ModeLocal || v.GetBool("showDebugLog") {
+		return slog.LevelDebug, nil
+	}
+
+	return slog.LevelInfo, nil
+}
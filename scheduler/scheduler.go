@@ -3,8 +3,10 @@ package scheduler
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,6 +27,8 @@ func NewScheduler(opts ...func(*Scheduler)) (s *Scheduler) {
 		s.ctx, s.cancel = context.WithCancel(context.Background())
 	}
 
+	s.alive.Store(true)
+	s.wait.Add(1)
 	go s.run()
 	return
 }
@@ -35,6 +39,7 @@ type Scheduler struct {
 	ctx    context.Context
 	cancel func()
 	wait   sync.WaitGroup
+	alive  atomic.Bool
 }
 
 func (s *Scheduler) Register(job *Job) {
@@ -51,7 +56,7 @@ func (s *Scheduler) RegisterAndRunImmediately(job *Job) {
 
 	err := job.Run()
 	if err != nil {
-		log.Printf("[scheduler] job error: %s", err.Error())
+		slog.Error("scheduler job failed", "error", err)
 	}
 
 	if !job.IsOnce() {
@@ -65,7 +70,8 @@ func (s *Scheduler) MustRegisterAndRunImmediately(job *Job) {
 
 	err := job.Run()
 	if err != nil {
-		log.Panicf("[scheduler] job error: %s", err.Error())
+		slog.Error("scheduler job failed", "error", err)
+		panic(err)
 	}
 
 	if !job.IsOnce() {
@@ -93,8 +99,21 @@ func (s *Scheduler) Close() {
 	s.wait.Wait()
 }
 
+// Check 检查调度循环是否存活, 可注册到 health.Registry
+func (s *Scheduler) Check(ctx context.Context) error {
+	if !s.alive.Load() {
+		return errors.New("scheduler loop is not running")
+	}
+
+	return nil
+}
+
 func (s *Scheduler) run() {
-	s.wait.Add(1)
+	s.alive.Store(true)
+	defer func() {
+		s.alive.Store(false)
+		s.wait.Done()
+	}()
 	for {
 		select {
 		case <-s.ctx.Done():
@@ -104,7 +123,7 @@ func (s *Scheduler) run() {
 			if job != nil {
 				err := job.Run()
 				if err != nil {
-					log.Printf("[scheduler] job error: %s", err.Error())
+					slog.Error("scheduler job failed", "error", err)
 				}
 				if !job.IsOnce() {
 					s.Register(job)
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -24,3 +25,15 @@ func TestNormal(t *testing.T) {
 
 	<-ch
 }
+
+func TestCheck(t *testing.T) {
+	s := NewScheduler()
+	if err := s.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+	if err := s.Check(context.Background()); err == nil {
+		t.Fatal("closed scheduler should not be alive")
+	}
+}